@@ -0,0 +1,85 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+)
+
+// AccuracyOption defines options for MapAccuracy.
+type AccuracyOption struct {
+	// Color is a color of the accuracy circle, usually translucent.
+	Color color.Color
+}
+
+// DefaultAccuracy is the default accuracy option.
+var DefaultAccuracy = &AccuracyOption{
+	Color: color.NRGBA{0x20, 0x80, 0xff, 0x50},
+}
+
+// MapAccuracy is like Render, but draws a single pin at the coordinate
+// with a circle around it showing the accuracy of the location, such as
+// GPS accuracy, as the radius in meters. The circle is drawn beneath
+// the pin, and the crop, if any, contains the whole circle.
+//
+// The radius is converted to pixels using the scale of the projection
+// at the coordinate, so the circle of the same radius in meters is larger
+// in pixels at higher latitudes on Mercator maps, which stretch areas
+// away from the equator.
+//
+// If aopt is nil, DefaultAccuracy is used. See Render for
+// the description of pin parts and options.
+func MapAccuracy(worldMap image.Image, pinParts []image.Image, c Coord, meters float64, aopt *AccuracyOption, opt *Options) image.Image {
+	if aopt == nil {
+		aopt = DefaultAccuracy
+	}
+	// Include the sides of the circle in the crop.
+	var sides []Coord
+	for _, bearing := range []float64{0, 90, 180, 270} {
+		sides = append(sides, destination(c, bearing, meters))
+	}
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		coords: []Coord{c},
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts}
+		},
+		extra: sides,
+		onMap: func(m *image.RGBA, cs []image.Point, l layout) {
+			x, y := pixelCenter(cs[0])
+			fillCircle(m, x, y, pixelRadius(l, c, meters), aopt.Color)
+		},
+	})
+	return finish(m, r, opt)
+}
+
+// pixelRadius returns the radius in pixels on the canvas with the layout
+// of the circle with the given radius in meters centered at c.
+func pixelRadius(l layout, c Coord, meters float64) float64 {
+	// Measure on a large map to keep precision for small circles.
+	w, h := l.mapRect.Dx(), l.mapRect.Dy()
+	k := math.Max(1, float64(extentSize)/float64(w))
+	kw, kh := int(float64(w)*k), int(float64(h)*k)
+	p := l.proj.Convert(c, kw, kh)
+	d := 0.0
+	for _, bearing := range []float64{0, 90, 180, 270} {
+		q := l.proj.Convert(destination(c, bearing, meters), kw, kh)
+		d += math.Hypot(float64(q.X-p.X), float64(q.Y-p.Y))
+	}
+	return d / 4 / k
+}
+
+// destination returns the coordinate at the given distance in meters
+// from c along the great circle with the initial bearing in degrees.
+func destination(c Coord, bearing, meters float64) Coord {
+	lat := c.Lat * math.Pi / 180
+	long := c.Long * math.Pi / 180
+	b := bearing * math.Pi / 180
+	d := meters / earthRadius
+	lat2 := math.Asin(math.Sin(lat)*math.Cos(d) + math.Cos(lat)*math.Sin(d)*math.Cos(b))
+	long2 := long + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat), math.Cos(d)-math.Sin(lat)*math.Sin(lat2))
+	return Coord{
+		Lat:  lat2 * 180 / math.Pi,
+		Long: math.Remainder(long2*180/math.Pi, 360),
+	}
+}
@@ -0,0 +1,34 @@
+package onmap_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapAccuracy(t *testing.T) {
+	worldMap := solidMap(2000, 2000, color.White)
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	aopt := &onmap.AccuracyOption{Color: blue}
+	const meters = 200000
+
+	// Count circle pixels at the equator and in Scandinavia.
+	equator := onmap.MapAccuracy(worldMap, nil, onmap.Coord{0, 10}, meters, aopt, nil)
+	north := onmap.MapAccuracy(worldMap, nil, onmap.Coord{65, 10}, meters, aopt, nil)
+	e, n := countColor(equator, blue), countColor(north, blue)
+	if e == 0 {
+		t.Fatalf("expected circle at the equator")
+	}
+	// Mercator scale at 65° is 1/cos(65°) ≈ 2.37, so the area is ≈ 5.6 times larger.
+	if n < 4*e {
+		t.Errorf("expected larger circle at high latitude, got %d and %d pixels", e, n)
+	}
+
+	// The crop contains the whole circle.
+	m := onmap.MapAccuracy(worldMap, nil, onmap.Coord{65, 10}, meters, aopt,
+		&onmap.Options{Crop: &onmap.CropOption{Bound: 2}})
+	if got := countColor(m, blue); got != n {
+		t.Errorf("expected %d circle pixels in the crop, got %d", n, got)
+	}
+}
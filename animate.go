@@ -0,0 +1,123 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// AnimatedPin is a pin that appears at the given frame of an animation.
+type AnimatedPin struct {
+	Coord
+
+	// Frame is the index of the first frame showing the pin.
+	Frame int
+}
+
+// AnimationOption defines options for animations.
+type AnimationOption struct {
+	// PopFrames is the number of frames over which a newly appeared pin
+	// grows to its full size, easing in. If zero or negative, pins appear
+	// at full size.
+	PopFrames int
+}
+
+// popFrames returns the number of frames over which pins grow.
+func (a *AnimationOption) popFrames() int {
+	if a == nil || a.PopFrames < 0 {
+		return 0
+	}
+	return a.PopFrames
+}
+
+// AnimatePins returns the given number of animation frames with pins
+// appearing on the world map at their frames, or none if the number
+// is not positive. The crop rectangle
+// is computed once from all pins, so the framing is stable across frames.
+//
+// If anim is nil, pins appear at full size. See Render for
+// the description of pin parts and options.
+func AnimatePins(worldMap image.Image, pinParts []image.Image, pins []AnimatedPin, frames int, anim *AnimationOption, opt *Options) []image.Image {
+	coords := make([]Coord, len(pins))
+	for i, p := range pins {
+		coords[i] = p.Coord
+	}
+
+	popFrames := anim.popFrames()
+	// Pin parts scaled for each frame of the pop-in ramp.
+	ramp := make([][]image.Image, popFrames)
+	for age := range ramp {
+		t := float64(age+1) / float64(popFrames+1)
+		s := t * t
+		ramp[age] = make([]image.Image, len(pinParts))
+		for i, p := range pinParts {
+			ramp[age][i] = scaleBy(p, s)
+		}
+	}
+
+	if frames < 0 {
+		frames = 0
+	}
+	out := make([]image.Image, frames)
+	for f := range out {
+		var shown []Coord
+		var parts [][]image.Image
+		for _, p := range pins {
+			age := f - p.Frame
+			if age < 0 {
+				continue
+			}
+			shown = append(shown, p.Coord)
+			if age < popFrames {
+				parts = append(parts, ramp[age])
+			} else {
+				parts = append(parts, pinParts)
+			}
+		}
+		// Shown pins are followed by all pins, which define the crop.
+		m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+			coords: shown,
+			mk: func(i int, pt image.Point) marker {
+				return marker{pt: pt, parts: parts[i]}
+			},
+			extra: coords,
+		})
+		out[f] = finish(m, r, opt)
+	}
+	return out
+}
+
+// MapPinsGIF writes an animated GIF with the given coordinates marked
+// as default pins on the default map appearing one at a time: the k-th
+// frame shows the first k pins. Each frame is shown for delayPerFrame
+// hundredths of a second. If crop is nil, doesn't crop the image.
+func MapPinsGIF(w io.Writer, coords []Coord, crop *CropOption, delayPerFrame int) error {
+	worldMap, err := loadMap()
+	if err != nil {
+		return err
+	}
+	pinParts, err := loadPin()
+	if err != nil {
+		return err
+	}
+	pins := make([]AnimatedPin, len(coords))
+	for i, c := range coords {
+		pins[i] = AnimatedPin{Coord: c, Frame: i}
+	}
+	frames := AnimatePins(worldMap, pinParts, pins, len(coords), nil, &Options{Crop: crop})
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, len(frames)),
+		Delay: make([]int, len(frames)),
+	}
+	for i, f := range frames {
+		b := f.Bounds()
+		p := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), palette.Plan9)
+		draw.FloydSteinberg.Draw(p, p.Bounds(), f, b.Min)
+		g.Image[i] = p
+		g.Delay[i] = delayPerFrame
+	}
+	return gif.EncodeAll(w, g)
+}
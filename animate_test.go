@@ -0,0 +1,87 @@
+package onmap_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func countNotColor(m image.Image, c color.Color) int {
+	n := 0
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !sameColor(m.At(x, y), c) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestAnimatePinsPopIn(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	pins := []onmap.AnimatedPin{{Coord: onmap.Coord{0, 0}, Frame: 1}}
+	frames := onmap.AnimatePins(worldMap, onmap.DefaultPin(), pins, 5, &onmap.AnimationOption{PopFrames: 2}, nil)
+	if len(frames) != 5 {
+		t.Fatalf("expected 5 frames, got %d", len(frames))
+	}
+	var counts []int
+	for _, f := range frames {
+		counts = append(counts, countNotColor(f, color.White))
+	}
+	if counts[0] != 0 {
+		t.Errorf("expected no pin in frame 0, got %d pixels", counts[0])
+	}
+	if !(0 < counts[1] && counts[1] < counts[2] && counts[2] < counts[3]) {
+		t.Errorf("expected pin to grow in frames 1-3, got pixel counts %v", counts)
+	}
+	if counts[3] != counts[4] {
+		t.Errorf("expected full size pin in frames 3-4, got pixel counts %v", counts)
+	}
+}
+
+func TestAnimatePinsNegative(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	pins := []onmap.AnimatedPin{{Coord: onmap.Coord{0, 0}, Frame: 0}}
+	frames := onmap.AnimatePins(worldMap, onmap.DefaultPin(), pins, 2, &onmap.AnimationOption{PopFrames: -1}, nil)
+	full := onmap.Render(worldMap, onmap.DefaultPin(), []onmap.Coord{{0, 0}}, nil)
+	if len(frames) != 2 || !sameImage(frames[0], full) {
+		t.Errorf("expected 2 frames with full size pins for negative PopFrames")
+	}
+	if frames := onmap.AnimatePins(worldMap, onmap.DefaultPin(), pins, -1, nil, nil); len(frames) != 0 {
+		t.Errorf("expected no frames for negative number, got %d", len(frames))
+	}
+}
+
+func TestMapPinsGIF(t *testing.T) {
+	coords := []onmap.Coord{
+		{51.5, -0.1}, // London
+		{48.9, 2.4},  // Paris
+		{52.5, 13.4}, // Berlin
+	}
+	var buf bytes.Buffer
+	if err := onmap.MapPinsGIF(&buf, coords, onmap.StandardCrop, 50); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != len(coords) {
+		t.Fatalf("expected %d frames, got %d", len(coords), len(g.Image))
+	}
+	size := onmap.Pins(coords, onmap.StandardCrop).Bounds().Size()
+	for i, m := range g.Image {
+		if m.Bounds().Size() != size {
+			t.Errorf("frame %d: expected size %v, got %v", i, size, m.Bounds().Size())
+		}
+		if g.Delay[i] != 50 {
+			t.Errorf("frame %d: expected delay 50, got %d", i, g.Delay[i])
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+)
+
+// ArcOption defines options for MapArcs.
+type ArcOption struct {
+	// Color is a color of arcs.
+	Color color.Color
+
+	// Width is a width of arcs in pixels.
+	Width float64
+
+	// Segments is the number of straight segments each arc is
+	// approximated with. If zero, 64 is used.
+	Segments int
+}
+
+// DefaultArc is the default arc option.
+var DefaultArc = &ArcOption{
+	Color: color.RGBA{0x20, 0x60, 0xe0, 0xff},
+	Width: 2,
+}
+
+// MapArcs is like Render, but draws the shortest paths along the surface
+// of the Earth (great-circle arcs) connecting each pair of coordinates,
+// and pins at both ends of each arc. Arcs are drawn beneath pins.
+// Arcs crossing the antimeridian are split at it, continuing from
+// the other side of the map. The crop, if any, contains whole arcs.
+//
+// If arc is nil, DefaultArc is used. See Render for
+// the description of pin parts and options.
+func MapArcs(worldMap image.Image, pinParts []image.Image, pairs [][2]Coord, arc *ArcOption, opt *Options) image.Image {
+	if arc == nil {
+		arc = DefaultArc
+	}
+	segments := arc.Segments
+	if segments <= 0 {
+		segments = 64
+	}
+
+	// Project pins first, then all points of arcs.
+	var coords []Coord
+	for _, p := range pairs {
+		coords = append(coords, p[0], p[1])
+	}
+	var paths [][]Coord
+	var points []Coord
+	for _, p := range pairs {
+		paths = append(paths, splitAntimeridian(greatCircle(p[0], p[1], segments))...)
+	}
+	for _, path := range paths {
+		points = append(points, path...)
+	}
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		coords: coords,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts}
+		},
+		extra: points,
+		onMap: func(m *image.RGBA, cs []image.Point, l layout) {
+			i := len(coords)
+			for _, path := range paths {
+				strokePolyline(m, cs[i:i+len(path)], l.px(arc.Width), arc.Color)
+				i += len(path)
+			}
+		},
+	})
+	return finish(m, r, opt)
+}
+
+// greatCircle returns n+1 coordinates evenly spaced
+// along the great-circle arc from a to b.
+func greatCircle(a, b Coord, n int) []Coord {
+	ax, ay, az := unitVector(a)
+	bx, by, bz := unitVector(b)
+	d := math.Acos(math.Max(-1, math.Min(1, ax*bx+ay*by+az*bz)))
+	path := make([]Coord, n+1)
+	for i := range path {
+		t := float64(i) / float64(n)
+		if d == 0 {
+			path[i] = a
+			continue
+		}
+		// Spherical linear interpolation.
+		ka := math.Sin((1-t)*d) / math.Sin(d)
+		kb := math.Sin(t*d) / math.Sin(d)
+		x, y, z := ka*ax+kb*bx, ka*ay+kb*by, ka*az+kb*bz
+		path[i] = Coord{
+			Lat:  math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi,
+			Long: math.Atan2(y, x) * 180 / math.Pi,
+		}
+	}
+	path[0], path[n] = a, b
+	return path
+}
+
+// unitVector returns the point on the unit sphere for the coordinate.
+func unitVector(c Coord) (x, y, z float64) {
+	lat := c.Lat * math.Pi / 180
+	long := c.Long * math.Pi / 180
+	return math.Cos(lat) * math.Cos(long), math.Cos(lat) * math.Sin(long), math.Sin(lat)
+}
+
+// splitAntimeridian splits the path into parts where it crosses
+// the antimeridian, that is, where the longitude of consecutive
+// coordinates differs by more than 180°, ending each part at the
+// edge of the map and starting the next one at the opposite edge.
+func splitAntimeridian(path []Coord) [][]Coord {
+	var parts [][]Coord
+	start := 0
+	var cur []Coord
+	for i := 1; i < len(path); i++ {
+		p, q := path[i-1], path[i]
+		if math.Abs(q.Long-p.Long) <= 180 {
+			continue
+		}
+		// Longitude of the edge crossed and of the opposite edge.
+		edge := 180.0
+		if p.Long < 0 {
+			edge = -180
+		}
+		// Find the latitude at the crossing of the unwrapped segment.
+		ql := q.Long + 2*edge
+		t := (edge - p.Long) / (ql - p.Long)
+		lat := p.Lat + t*(q.Lat-p.Lat)
+		cur = append(cur, path[start:i]...)
+		parts = append(parts, append(cur, Coord{lat, edge}))
+		cur = []Coord{{lat, -edge}}
+		start = i
+	}
+	return append(parts, append(cur, path[start:]...))
+}
@@ -0,0 +1,41 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapArcs(t *testing.T) {
+	sf := onmap.Coord{37.7775, -122.416389}
+	moscow := onmap.Coord{55.755833, 37.617222}
+	m := onmap.MapArcs(onmap.DefaultMap(), onmap.DefaultPin(), [][2]onmap.Coord{{sf, moscow}}, nil,
+		&onmap.Options{Crop: onmap.StandardCrop})
+	if err := writePng("test-arc.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	worldMap := solidMap(720, 720, color.White)
+	arcColor := color.RGBA{0, 0, 0xff, 0xff}
+	arc := &onmap.ArcOption{Color: arcColor, Width: 3}
+	m = onmap.MapArcs(worldMap, nil, [][2]onmap.Coord{{sf, moscow}}, arc, nil)
+	// The great-circle arc goes far north of both ends.
+	p := onmap.Mercator.Convert(onmap.Coord{Lat: 57, Long: 0}, 720, 720)
+	if countColor(m.(*image.RGBA).SubImage(image.Rect(p.X-2, 0, p.X+2, p.Y)), arcColor) == 0 {
+		t.Errorf("expected arc north of %v at the prime meridian", p)
+	}
+
+	// Arc across the Pacific is split at the antimeridian
+	// instead of crossing the whole map.
+	tokyo := onmap.Coord{35.6895, 139.6917}
+	m = onmap.MapArcs(worldMap, nil, [][2]onmap.Coord{{tokyo, sf}}, arc, nil)
+	if n := countColor(m.(*image.RGBA).SubImage(image.Rect(355, 0, 365, 720)), arcColor); n != 0 {
+		t.Errorf("expected no arc at the prime meridian, got %d pixels", n)
+	}
+	if countColor(m.(*image.RGBA).SubImage(image.Rect(0, 0, 5, 720)), arcColor) == 0 ||
+		countColor(m.(*image.RGBA).SubImage(image.Rect(715, 0, 720, 720)), arcColor) == 0 {
+		t.Errorf("expected arc to reach both edges of the map")
+	}
+}
@@ -0,0 +1,75 @@
+package onmap
+
+import (
+	"image"
+	"math"
+	"sync"
+)
+
+// maxBases is the maximum number of cached maps of different widths.
+const maxBases = 8
+
+var (
+	basesMu sync.Mutex
+	bases   = make(map[int]*baseEntry)
+	widths  []int // cached widths from least to most recently used
+)
+
+type baseEntry struct {
+	once sync.Once
+	img  image.Image
+}
+
+// BaseAtWidth returns the default world map downscaled to the given width,
+// preserving its aspect ratio. Results for the few most recently used
+// widths are cached, so repeated renders of small maps can reuse them
+// instead of the full-size map. It is safe to call from
+// multiple goroutines. The returned image must not be modified.
+//
+// Since projections convert coordinates according to the map size,
+// the returned map can be used anywhere DefaultMap is used.
+func BaseAtWidth(w int) image.Image {
+	if w <= 0 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+	basesMu.Lock()
+	e, ok := bases[w]
+	if ok {
+		for i, x := range widths {
+			if x == w {
+				widths = append(widths[:i], widths[i+1:]...)
+				break
+			}
+		}
+	} else {
+		e = new(baseEntry)
+		bases[w] = e
+		if len(widths) == maxBases {
+			// Evict the least recently used map.
+			delete(bases, widths[0])
+			widths = widths[1:]
+		}
+	}
+	widths = append(widths, w)
+	basesMu.Unlock()
+
+	e.once.Do(func() {
+		m := DefaultMap()
+		b := m.Bounds()
+		if w == b.Dx() {
+			e.img = m
+			return
+		}
+		h := int(math.Round(float64(w) * float64(b.Dy()) / float64(b.Dx())))
+		e.img = scaleImage(m, w, h)
+	})
+	return e.img
+}
+
+// resetBases drops cached maps after the default map is replaced.
+func resetBases() {
+	basesMu.Lock()
+	bases = make(map[int]*baseEntry)
+	widths = nil
+	basesMu.Unlock()
+}
@@ -0,0 +1,52 @@
+package onmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestBaseAtWidth(t *testing.T) {
+	// Run with -race to check concurrent access.
+	widths := []int{320, 480, 640}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := widths[i%len(widths)]
+			first := onmap.BaseAtWidth(w)
+			for j := 0; j < 10; j++ {
+				if m := onmap.BaseAtWidth(w); m != first {
+					t.Errorf("width %d: got different images", w)
+					return
+				}
+			}
+			if first.Bounds().Dx() != w {
+				t.Errorf("expected width %d, got %d", w, first.Bounds().Dx())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	b := onmap.DefaultMap().Bounds()
+	m := onmap.BaseAtWidth(480)
+	if h := 480 * b.Dy() / b.Dx(); abs(m.Bounds().Dy()-h) > 1 {
+		t.Fatalf("expected height %d, got %d", h, m.Bounds().Dy())
+	}
+}
+
+func TestBaseAtWidthBounded(t *testing.T) {
+	recent := onmap.BaseAtWidth(64)
+	for w := 1; w <= 32; w++ {
+		onmap.BaseAtWidth(w)
+		// Keep using the same width.
+		if m := onmap.BaseAtWidth(64); m != recent {
+			t.Fatalf("expected recently used width to stay cached")
+		}
+	}
+	if n := onmap.CachedBases(); n > 8 {
+		t.Errorf("expected at most 8 cached maps, got %d", n)
+	}
+}
@@ -0,0 +1,81 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// BeeswarmOption defines options for MapBeeswarm.
+type BeeswarmOption struct {
+	// Radius is a maximum distance in pixels between projected
+	// points for them to be stacked together.
+	Radius int
+
+	// Spacing is a vertical distance in pixels between stacked markers.
+	Spacing int
+
+	// MarkerRadius is a radius of each marker in pixels.
+	MarkerRadius int
+
+	// Color is a color of markers.
+	Color color.Color
+
+	// StemColor is a color of the stem connecting markers to the ground point.
+	StemColor color.Color
+
+	// StemWidth is a width of the stem in pixels.
+	StemWidth float64
+}
+
+// DefaultBeeswarm is the default beeswarm option.
+var DefaultBeeswarm = &BeeswarmOption{
+	Radius:       4,
+	Spacing:      12,
+	MarkerRadius: 5,
+	Color:        color.RGBA{0xe0, 0x20, 0x20, 0xff},
+	StemColor:    color.RGBA{0x40, 0x40, 0x40, 0xff},
+	StemWidth:    2,
+}
+
+// MapBeeswarm returns an image with the given coordinates drawn as
+// markers on the given world map. Instead of drawing near-coincident
+// coordinates on top of each other, their markers are stacked upward
+// above the ground point, which is connected to the stack with a stem.
+//
+// If opt is nil, DefaultBeeswarm is used. If crop is nil, doesn't crop the image.
+func MapBeeswarm(proj Projection, worldMap image.Image, coords []Coord, opt *BeeswarmOption, crop *CropOption) image.Image {
+	if opt == nil {
+		opt = DefaultBeeswarm
+	}
+	mapWidth := worldMap.Bounds().Dx()
+	mapHeight := worldMap.Bounds().Dy()
+
+	cs := make([]image.Point, len(coords))
+	for i, c := range coords {
+		cs[i] = proj.Convert(c, mapWidth, mapHeight)
+	}
+
+	m := newCanvas(worldMap)
+	// The crop contains whole stacks.
+	bounds := append([]image.Point(nil), cs...)
+	for _, g := range groupPoints(cs, opt.Radius) {
+		gx, gy := pixelCenter(centroid(cs, g))
+		top := gy - float64(len(g)*opt.Spacing)
+		strokeLine(m, gx, gy, gx, top, opt.StemWidth, opt.StemColor)
+		for i := range g {
+			y := gy - float64((i+1)*opt.Spacing)
+			fillCircle(m, gx, y, float64(opt.MarkerRadius), opt.Color)
+		}
+		r := float64(opt.MarkerRadius)
+		bounds = append(bounds,
+			image.Pt(int(math.Floor(gx-r)), int(math.Floor(top-r))),
+			image.Pt(int(math.Ceil(gx+r)), int(math.Ceil(top-r))))
+	}
+
+	if crop == nil {
+		return m
+	}
+	l := layout{proj: proj, mapRect: m.Bounds(), canvas: m.Bounds()}
+	return m.SubImage(l.cropRect(bounds, crop))
+}
@@ -0,0 +1,48 @@
+package onmap_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapBeeswarm(t *testing.T) {
+	coords := make([]onmap.Coord, 5)
+	for i := range coords {
+		coords[i] = onmap.Coord{0, 0}
+	}
+	worldMap := solidMap(360, 360, color.White)
+	opt := *onmap.DefaultBeeswarm
+	m := onmap.MapBeeswarm(onmap.Mercator, worldMap, coords, &opt, nil)
+
+	// Equator and prime meridian are at the center of the map.
+	for i := 1; i <= len(coords); i++ {
+		y := 180 - i*opt.Spacing
+		if c := m.At(180, y); !sameColor(c, opt.Color) {
+			t.Errorf("marker %d: expected marker color at (180, %d), got %v", i, y, c)
+		}
+	}
+	y := 180 - (len(coords)+1)*opt.Spacing
+	if c := m.At(180, y); !sameColor(c, color.White) {
+		t.Errorf("expected no marker above the stack at (180, %d), got %v", y, c)
+	}
+}
+
+func TestMapBeeswarmCrop(t *testing.T) {
+	// A tall stack near the top edge of the crop.
+	coords := make([]onmap.Coord, 10)
+	for i := range coords {
+		coords[i] = onmap.Coord{0, 0}
+	}
+	coords = append(coords, onmap.Coord{-20, 20})
+	worldMap := solidMap(360, 360, color.White)
+	opt := *onmap.DefaultBeeswarm
+	m := onmap.MapBeeswarm(onmap.Mercator, worldMap, coords, &opt, &onmap.CropOption{Bound: 5})
+
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+	top := p.Y - 10*opt.Spacing - opt.MarkerRadius
+	if b := m.Bounds(); b.Min.Y > top {
+		t.Errorf("expected crop to include the top of the stack at %d, got %v", top, b)
+	}
+}
@@ -0,0 +1,55 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// BorderOption defines the border drawn around the image.
+type BorderOption struct {
+	// Color is the border color. If nil, black is used.
+	Color color.Color
+
+	// Width is the border width in pixels. If zero, 1 is used.
+	Width int
+}
+
+// draw draws the border inside the bounds of the image, modifying it
+// if possible, and returns the image.
+func (b *BorderOption) draw(m image.Image) image.Image {
+	dst, ok := m.(draw.Image)
+	if !ok {
+		dst = copyImage(m)
+	}
+	var c color.Color = color.Black
+	if b.Color != nil {
+		c = b.Color
+	}
+	w := b.width()
+	src := image.NewUniform(c)
+	r := dst.Bounds()
+	inner := r.Inset(w)
+	if inner.Empty() {
+		// The border covers the whole image.
+		draw.Draw(dst, r, src, image.Point{}, draw.Over)
+		return dst
+	}
+	for _, side := range []image.Rectangle{
+		{r.Min, image.Point{r.Max.X, inner.Min.Y}},                                 // top
+		{image.Point{r.Min.X, inner.Max.Y}, r.Max},                                 // bottom
+		{image.Point{r.Min.X, inner.Min.Y}, image.Point{inner.Min.X, inner.Max.Y}}, // left
+		{image.Point{inner.Max.X, inner.Min.Y}, image.Point{r.Max.X, inner.Max.Y}}, // right
+	} {
+		draw.Draw(dst, side, src, image.Point{}, draw.Over)
+	}
+	return dst
+}
+
+// width returns the border width.
+func (b *BorderOption) width() int {
+	if b.Width <= 0 {
+		return 1
+	}
+	return b.Width
+}
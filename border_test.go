@@ -0,0 +1,70 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func checkBorder(t *testing.T, m image.Image, c color.Color, width int) {
+	t.Helper()
+	b := m.Bounds()
+	want := color.RGBAModel.Convert(c)
+	inner := b.Inset(width)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			got := color.RGBAModel.Convert(m.At(x, y))
+			inBorder := !(image.Point{x, y}).In(inner)
+			if inBorder && got != want {
+				t.Fatalf("expected border color %v at (%d, %d), got %v", want, x, y, got)
+			}
+		}
+	}
+	// Just inside the border the map is visible.
+	if got := color.RGBAModel.Convert(m.At(inner.Min.X, inner.Min.Y+inner.Dy()/2)); got == want {
+		t.Errorf("expected map inside the border, got border color")
+	}
+}
+
+func TestRenderBorder(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{41.9097306, 12.2558141}, // Rome
+	}
+	red := color.RGBA{0xc0, 0x10, 0x10, 0xff}
+	border := &onmap.BorderOption{Color: red, Width: 3}
+
+	cropped := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+		Crop:   onmap.StandardCrop,
+		Border: border,
+	})
+	checkBorder(t, cropped, red, 3)
+	if err := writePng("test-border.png", cropped); err != nil {
+		t.Fatal(err)
+	}
+
+	full := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{Border: border})
+	if full.Bounds().Size() != onmap.DefaultMap().Bounds().Size() {
+		t.Errorf("expected border to keep the image size, got %v", full.Bounds())
+	}
+	checkBorder(t, full, red, 3)
+
+	resized := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+		Crop:   onmap.StandardCrop,
+		Target: &onmap.TargetOption{Width: 200, Height: 100},
+		Border: border,
+	})
+	if s := resized.Bounds().Size(); s != (image.Point{200, 100}) {
+		t.Errorf("expected 200x100 image, got %v", s)
+	}
+	checkBorder(t, resized, red, 3)
+
+	scaled := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+		Crop:   onmap.StandardCrop,
+		Border: border,
+		Scale:  2,
+	})
+	checkBorder(t, scaled, red, 6)
+}
@@ -0,0 +1,86 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// CanvasFit defines how the world map is fitted into the canvas.
+type CanvasFit int
+
+const (
+	// FitContain scales the map to fit entirely into the canvas,
+	// letterboxing it with the background.
+	FitContain CanvasFit = iota
+
+	// FitCover scales the map to cover the whole canvas,
+	// cutting off its parts that don't fit.
+	FitCover
+)
+
+// CanvasOption defines a canvas of arbitrary size into which
+// the world map is scaled and centered.
+type CanvasOption struct {
+	// Width is the width of the canvas.
+	Width int
+
+	// Height is the height of the canvas.
+	Height int
+
+	// Background is the color of the canvas outside of the map.
+	// If nil, it's transparent.
+	Background color.Color
+
+	// Fit defines how the map is fitted into the canvas.
+	Fit CanvasFit
+}
+
+// mapRect returns the rectangle of the canvas
+// occupied by the scaled and centered world map.
+func (c *CanvasOption) mapRect(worldMap image.Image) image.Rectangle {
+	b := worldMap.Bounds()
+	sx := float64(c.Width) / float64(b.Dx())
+	sy := float64(c.Height) / float64(b.Dy())
+	s := math.Min(sx, sy)
+	if c.Fit == FitCover {
+		s = math.Max(sx, sy)
+	}
+	w := int(math.Round(float64(b.Dx()) * s))
+	h := int(math.Round(float64(b.Dy()) * s))
+	return image.Rect(0, 0, w, h).Add(image.Point{(c.Width - w) / 2, (c.Height - h) / 2})
+}
+
+// TargetOption defines the exact size of the final image.
+type TargetOption struct {
+	// Width is the width of the image.
+	Width int
+
+	// Height is the height of the image.
+	Height int
+
+	// Letterbox, if true, preserves the aspect ratio of the image
+	// by fitting it into the target size and centering it over
+	// the background. Otherwise the image is stretched.
+	Letterbox bool
+
+	// Background is the color of the letterbox.
+	// If nil, it's transparent.
+	Background color.Color
+}
+
+// resize returns the image resized to the target size.
+func (t *TargetOption) resize(m image.Image) image.Image {
+	if !t.Letterbox {
+		return scaleImage(m, t.Width, t.Height)
+	}
+	c := &CanvasOption{Width: t.Width, Height: t.Height, Fit: FitContain}
+	r := c.mapRect(m)
+	dst := image.NewRGBA(image.Rect(0, 0, t.Width, t.Height))
+	if t.Background != nil {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(t.Background), image.Point{}, draw.Src)
+	}
+	draw.Draw(dst, r, scaleImage(m, r.Dx(), r.Dy()), image.Point{}, draw.Over)
+	return dst
+}
@@ -0,0 +1,57 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderCanvas(t *testing.T) {
+	land := color.RGBA{0x20, 0x80, 0x20, 0xff}
+	bg := color.RGBA{0x10, 0x10, 0x10, 0xff}
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	worldMap := solidMap(400, 200, land)
+	dot := solidMap(3, 3, red)
+	opt := &onmap.Options{
+		Canvas: &onmap.CanvasOption{Width: 300, Height: 300, Background: bg, Fit: onmap.FitContain},
+	}
+	m := onmap.Render(worldMap, []image.Image{dot}, []onmap.Coord{{0, 0}}, opt)
+	if b := m.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+		t.Fatalf("expected 300x300 image, got %v", b)
+	}
+	// Map is scaled to 300x150 and letterboxed vertically.
+	if c := m.At(150, 10); !sameColor(c, bg) {
+		t.Errorf("expected background at (150, 10), got %v", c)
+	}
+	if c := m.At(150, 290); !sameColor(c, bg) {
+		t.Errorf("expected background at (150, 290), got %v", c)
+	}
+	if c := m.At(10, 100); !sameColor(c, land) {
+		t.Errorf("expected map at (10, 100), got %v", c)
+	}
+	// Pin at the center of the scaled map.
+	p := onmap.Mercator.Convert(onmap.Coord{0, 0}, 300, 150).Add(image.Point{0, 75})
+	if c := m.At(p.X, p.Y-1); !sameColor(c, red) {
+		t.Errorf("expected pin at %v, got %v", p, c)
+	}
+}
+
+func TestRenderTarget(t *testing.T) {
+	coords := []onmap.Coord{{42.1, 19.1}, {41.9097306, 12.2558141}}
+	bg := color.RGBA{0x10, 0x20, 0x30, 0xff}
+	for _, letterbox := range []bool{false, true} {
+		m := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+			Crop:   &onmap.CropOption{Bound: 50},
+			Target: &onmap.TargetOption{Width: 200, Height: 200, Letterbox: letterbox, Background: bg},
+		})
+		if b := m.Bounds(); b != image.Rect(0, 0, 200, 200) {
+			t.Fatalf("letterbox %v: expected 200x200 image, got %v", letterbox, b)
+		}
+		// The crop is wider than tall, so letterboxing adds bars.
+		if hasBar := sameColor(m.At(100, 0), bg); hasBar != letterbox {
+			t.Errorf("letterbox %v: unexpected color at the top %v", letterbox, m.At(100, 0))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package onmap
+
+import (
+	"fmt"
+	"image"
+)
+
+// aspectRanger is implemented by projections that know
+// the usual range of width to height ratio of world maps.
+type aspectRanger interface {
+	aspectRange() (min, max float64)
+}
+
+// aspectRange returns the range of aspect ratios of Mercator maps:
+// they are square when covering latitudes up to ±85.05°, and
+// wider when cropped at lower latitudes to drop polar regions.
+func (p mercatorProjection) aspectRange() (min, max float64) {
+	return 0.9, 1.5
+}
+
+// aspectRange returns the range of aspect ratios of equirectangular maps,
+// which are normally twice as wide as they are tall.
+func (p equirectangularProjection) aspectRange() (min, max float64) {
+	return 1.8, 2.2
+}
+
+// aspectRange returns the range of aspect ratios of Web Mercator maps,
+// which are square.
+func (p webMercatorProjection) aspectRange() (min, max float64) {
+	return 0.9, 1.1
+}
+
+// CheckMapForProjection performs heuristic sanity checks of whether
+// the world map is likely to be in the given projection and returns
+// a descriptive error if it's probably not, for example, when
+// a 2:1 equirectangular map is used with Mercator projection.
+//
+// Returns nil if the map looks fine or if the projection
+// provides no information to check against.
+func CheckMapForProjection(worldMap image.Image, proj Projection) error {
+	b := worldMap.Bounds()
+	if b.Empty() {
+		return fmt.Errorf("onmap: world map is empty")
+	}
+	ar, ok := proj.(aspectRanger)
+	if !ok {
+		return nil
+	}
+	min, max := ar.aspectRange()
+	ratio := float64(b.Dx()) / float64(b.Dy())
+	if ratio < min || ratio > max {
+		return fmt.Errorf("onmap: world map aspect ratio %.2f is outside of the expected range %.2f-%.2f for the projection", ratio, min, max)
+	}
+	return nil
+}
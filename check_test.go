@@ -0,0 +1,26 @@
+package onmap_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestCheckMapForProjection(t *testing.T) {
+	if err := onmap.CheckMapForProjection(onmap.DefaultMap(), onmap.Mercator); err != nil {
+		t.Errorf("unexpected error for the embedded map: %v", err)
+	}
+	if err := onmap.CheckMapForProjection(solidMap(720, 360, color.White), onmap.Mercator); err == nil {
+		t.Errorf("expected error for 2:1 map with Mercator")
+	}
+}
+
+func TestCheckMapForEquirectangular(t *testing.T) {
+	if err := onmap.CheckMapForProjection(solidMap(720, 360, color.White), onmap.Equirectangular); err != nil {
+		t.Errorf("unexpected error for 2:1 map: %v", err)
+	}
+	if err := onmap.CheckMapForProjection(onmap.DefaultMap(), onmap.Equirectangular); err == nil {
+		t.Errorf("expected error for Mercator map with Equirectangular")
+	}
+}
@@ -0,0 +1,122 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+)
+
+// ClusterOption defines options for clustering nearby pins.
+type ClusterOption struct {
+	// Radius is the maximum distance in pixels between points
+	// clustered together.
+	Radius int
+
+	// DisplayWidth, if not zero, makes Radius relative to the output
+	// image scaled to this width: the radius in pixels of the map is
+	// Radius multiplied by the crop width and divided by DisplayWidth.
+	// This way the same points are clustered in wide views
+	// and shown individually in tight crops.
+	DisplayWidth int
+
+	// BadgeColor is the color of badges with the number of clustered
+	// points. If nil, dark gray is used.
+	BadgeColor color.Color
+}
+
+// radius returns the clustering radius in map pixels for the crop rectangle.
+func (o *ClusterOption) radius(crop image.Rectangle) int {
+	if o.DisplayWidth == 0 {
+		return o.Radius
+	}
+	return int(math.Round(float64(o.Radius) * float64(crop.Dx()) / float64(o.DisplayWidth)))
+}
+
+// Cluster is a group of nearby points.
+type Cluster struct {
+	// Point is the centroid of the points.
+	Point image.Point
+
+	// Members are indexes of the clustered points.
+	Members []int
+}
+
+// ClusterPoints groups points that are within the given radius
+// from the first point of a group into clusters.
+func ClusterPoints(points []image.Point, radius int) []Cluster {
+	groups := groupPoints(points, radius)
+	clusters := make([]Cluster, len(groups))
+	for i, g := range groups {
+		clusters[i] = Cluster{centroid(points, g), g}
+	}
+	return clusters
+}
+
+// clusterMarkers returns markers for clusters of points and badges
+// with the number of points for clusters of more than one point.
+// Cluster markers are made by mk for the first point of each cluster
+// and moved to the cluster centroid.
+func clusterMarkers(points []image.Point, radius int, mk func(i int, pt image.Point) marker) (markers []marker, badges []badge) {
+	for _, c := range ClusterPoints(points, radius) {
+		m := mk(c.Members[0], c.Point)
+		markers = append(markers, m)
+		if len(c.Members) > 1 {
+			r := pinRect(c.Point, m.parts)
+			badges = append(badges, badge{image.Point{r.Max.X, r.Min.Y}, strconv.Itoa(len(c.Members))})
+		}
+	}
+	return markers, badges
+}
+
+// badge is a number drawn in a circle centered at the point.
+type badge struct {
+	pt   image.Point
+	text string
+}
+
+// drawBadges draws badges onto the image.
+func drawBadges(m draw.Image, badges []badge, c color.Color) {
+	for _, b := range badges {
+		size := textSize(b.text, 1)
+		x, y := pixelCenter(b.pt)
+		fillCircle(m, x, y, float64(size.X)/2+3, c)
+		drawText(m, b.pt.Sub(size.Div(2)), b.text, 1, color.White)
+	}
+}
+
+// groupPoints groups points that are within the given radius
+// from the first point of a group. It returns groups of indexes
+// into points in the order of their first appearance.
+func groupPoints(points []image.Point, radius int) [][]int {
+	var groups [][]int
+	r2 := radius * radius
+	for i, p := range points {
+		found := false
+		for j, g := range groups {
+			q := points[g[0]]
+			dx, dy := p.X-q.X, p.Y-q.Y
+			if dx*dx+dy*dy <= r2 {
+				groups[j] = append(g, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []int{i})
+		}
+	}
+	return groups
+}
+
+// centroid returns the centroid of points with the given indexes.
+func centroid(points []image.Point, indexes []int) image.Point {
+	var x, y float64
+	for _, i := range indexes {
+		x += float64(points[i].X)
+		y += float64(points[i].Y)
+	}
+	n := float64(len(indexes))
+	return image.Point{int(math.Round(x / n)), int(math.Round(y / n))}
+}
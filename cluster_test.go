@@ -0,0 +1,98 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func countColor(m image.Image, c color.Color) int {
+	n := 0
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if sameColor(m.At(x, y), c) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRenderClusterByZoom(t *testing.T) {
+	worldMap := solidMap(3600, 3600, color.White)
+	dot := solidMap(4, 4, color.RGBA{0xff, 0, 0, 0xff})
+	coords := []onmap.Coord{{0, 0}, {0, 1}}
+	badgeColor := color.RGBA{0, 0, 0xff, 0xff}
+	cluster := &onmap.ClusterOption{Radius: 20, DisplayWidth: 200, BadgeColor: badgeColor}
+
+	wide := onmap.Render(worldMap, []image.Image{dot}, coords, &onmap.Options{
+		Crop:    &onmap.CropOption{Bound: 20, MinWidth: 3000, MinHeight: 1000},
+		Cluster: cluster,
+	})
+	if countColor(wide, badgeColor) == 0 {
+		t.Errorf("expected clustered pins with a badge in the wide crop")
+	}
+
+	tight := onmap.Render(worldMap, []image.Image{dot}, coords, &onmap.Options{
+		Crop:    &onmap.CropOption{Bound: 20},
+		Cluster: cluster,
+	})
+	if countColor(tight, badgeColor) != 0 {
+		t.Errorf("expected individual pins without badges in the tight crop")
+	}
+}
+
+func TestRenderClusterFixedBounds(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	dot := solidMap(4, 4, color.RGBA{0xff, 0, 0, 0xff})
+	// The second pin is outside of the window, but near the first one.
+	coords := []onmap.Coord{{0, 0}, {0, 15}}
+	badgeColor := color.RGBA{0, 0, 0xff, 0xff}
+	m := onmap.Render(worldMap, []image.Image{dot}, coords, &onmap.Options{
+		Crop:    &onmap.CropOption{FixedBounds: &onmap.Bounds{SW: onmap.Coord{-10, -10}, NE: onmap.Coord{10, 10}}},
+		Cluster: &onmap.ClusterOption{Radius: 50, BadgeColor: badgeColor},
+	})
+	if countColor(m, badgeColor) != 0 {
+		t.Errorf("expected pins outside of the fixed window not to be clustered")
+	}
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+	if c := m.At(p.X, p.Y-1); !sameColor(c, color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("expected the pin inside the window at %v, got %v", p, c)
+	}
+}
+
+func TestClusterPoints(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{42.441286, 19.262892},   // Podgorica
+		{42.28, 18.84},           // Budva
+		{42.42, 18.77},           // Kotor
+		{42.39, 18.92},           // Cetinje
+		{-31.952222, 115.858889}, // Perth
+	}
+	b := onmap.DefaultMap().Bounds()
+	points := make([]image.Point, len(coords))
+	for i, c := range coords {
+		points[i] = onmap.Mercator.Convert(c, b.Dx(), b.Dy())
+	}
+	clusters := onmap.ClusterPoints(points, 20)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	if n := len(clusters[0].Members); n != 5 {
+		t.Fatalf("expected 5 members in the first cluster, got %d", n)
+	}
+	var sum image.Point
+	for _, i := range clusters[0].Members {
+		sum = sum.Add(points[i])
+	}
+	if d := clusters[0].Point.Sub(sum.Div(5)); abs(d.X) > 1 || abs(d.Y) > 1 {
+		t.Errorf("expected cluster at the centroid %v, got %v", sum.Div(5), clusters[0].Point)
+	}
+	if clusters[1].Point != points[5] {
+		t.Errorf("expected isolated point at %v, got %v", points[5], clusters[1].Point)
+	}
+}
@@ -0,0 +1,43 @@
+package onmap_test
+
+import (
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+// TestConcurrentRender checks that concurrent rendering with shared
+// inputs produces consistent results. Run with -race to detect data races.
+func TestConcurrentRender(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{41.9097306, 12.2558141}, // Rome
+		{55.755833, 37.617222},   // Moscow
+	}
+	worldMap := onmap.DefaultMap()
+	pinParts := onmap.DefaultPin()
+	want := onmap.Pins(coords, onmap.StandardCrop)
+
+	const n = 50
+	got := make([]image.Image, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				got[i] = onmap.Pins(coords, onmap.StandardCrop)
+			} else {
+				got[i] = onmap.MapPinsProjection(onmap.Mercator, worldMap, pinParts, coords, onmap.StandardCrop)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, m := range got {
+		if !sameImage(m, want) {
+			t.Errorf("goroutine %d: image differs", i)
+		}
+	}
+}
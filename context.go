@@ -0,0 +1,33 @@
+package onmap
+
+import (
+	"context"
+	"image"
+)
+
+// RenderContext is like Render, but checks the context between rendering
+// stages and returns the context error, if it's done, without finishing
+// rendering.
+func RenderContext(ctx context.Context, worldMap image.Image, pinParts []image.Image, coords []Coord, opt *Options) (image.Image, error) {
+	m, r, err := renderMarkersContext(ctx, worldMap, coords, opt, func(i int, pt image.Point) marker {
+		return marker{pt: pt, parts: pinParts}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return finish(m, r, opt), nil
+}
+
+// MapPinsContext is like MapPinsProjection, but checks the context between
+// rendering stages and returns the context error, if it's done, without
+// finishing rendering.
+func MapPinsContext(ctx context.Context, proj Projection, worldMap image.Image, pinParts []image.Image, coords []Coord, crop *CropOption) (image.Image, error) {
+	return RenderContext(ctx, worldMap, pinParts, coords, &Options{Projection: proj, Crop: crop})
+}
+
+// PinsContext is like Pins, but checks the context between rendering
+// stages and returns the context error, if it's done, without finishing
+// rendering.
+func PinsContext(ctx context.Context, coords []Coord, crop *CropOption) (image.Image, error) {
+	return MapPinsContext(ctx, Mercator, DefaultMap(), DefaultPin(), coords, crop)
+}
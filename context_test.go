@@ -0,0 +1,34 @@
+package onmap_test
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderContext(t *testing.T) {
+	coords := []onmap.Coord{{42.1, 19.1}, {41.9097306, 12.2558141}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m, err := onmap.PinsContext(ctx, coords, onmap.StandardCrop)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if m != nil {
+		t.Fatalf("expected no image for canceled context")
+	}
+
+	worldMap := solidMap(360, 360, color.White)
+	m, err = onmap.MapPinsContext(context.Background(), onmap.Mercator, worldMap, onmap.DefaultPin(), coords, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, nil)
+	if countNotColor(m, color.White) != countNotColor(expected, color.White) {
+		t.Errorf("expected the same image as MapPinsProjection")
+	}
+}
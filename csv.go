@@ -0,0 +1,54 @@
+package onmap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CoordsFromCSV reads CSV records and returns coordinates from the given
+// zero-based latitude and longitude columns of each record. If hasHeader
+// is true, the first record is skipped.
+func CoordsFromCSV(r io.Reader, latCol, longCol int, hasHeader bool) ([]Coord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	var coords []Coord
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return coords, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && hasHeader {
+			continue
+		}
+		lat, err := csvFloat(cr, rec, latCol)
+		if err != nil {
+			return nil, err
+		}
+		long, err := csvFloat(cr, rec, longCol)
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, Coord{Lat: lat, Long: long})
+	}
+}
+
+// csvFloat parses the number in the given column of the record
+// most recently read by the reader.
+func csvFloat(cr *csv.Reader, rec []string, col int) (float64, error) {
+	if col < 0 || col >= len(rec) {
+		line, _ := cr.FieldPos(0)
+		return 0, fmt.Errorf("onmap: CSV line %d has no column %d", line, col)
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(rec[col]), 64)
+	if err != nil {
+		line, _ := cr.FieldPos(col)
+		return 0, fmt.Errorf("onmap: CSV line %d, column %d: invalid number %q", line, col, rec[col])
+	}
+	return v, nil
+}
@@ -0,0 +1,46 @@
+package onmap_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestCoordsFromCSV(t *testing.T) {
+	want := []onmap.Coord{{51.5, -0.1}, {48.9, 2.4}}
+
+	coords, err := onmap.CoordsFromCSV(strings.NewReader("London,51.5,-0.1\nParis,48.9,2.4\n"), 1, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(coords, want) {
+		t.Errorf("expected %v, got %v", want, coords)
+	}
+
+	const header = "lon,lat,name\n-0.1,51.5,London\n2.4, 48.9 ,Paris\n"
+	coords, err = onmap.CoordsFromCSV(strings.NewReader(header), 1, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(coords, want) {
+		t.Errorf("with header: expected %v, got %v", want, coords)
+	}
+}
+
+func TestCoordsFromCSVErrors(t *testing.T) {
+	for _, s := range []string{
+		"51.5,-0.1\n48.9,east\n",
+		"51.5,-0.1\n48.9\n",
+	} {
+		_, err := onmap.CoordsFromCSV(strings.NewReader(s), 0, 1, false)
+		if err == nil {
+			t.Errorf("%q: expected error", s)
+			continue
+		}
+		if !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%q: expected line number in error, got %q", s, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Colors of pins drawn by MapDiff.
+var (
+	DiffAddedColor     color.Color = color.RGBA{0x30, 0xa0, 0x30, 0xff}
+	DiffRemovedColor   color.Color = color.RGBA{0xd0, 0x30, 0x30, 0xff}
+	DiffUnchangedColor color.Color = color.RGBA{0x90, 0x90, 0x90, 0xff}
+)
+
+// MapDiff returns an image comparing two sets of coordinates on the world
+// map using the embedded pin: pins present only in setB are drawn with
+// DiffAddedColor, pins present only in setA with DiffRemovedColor,
+// and pins present in both with DiffUnchangedColor. Pins from the sets
+// are considered the same if their projected points are within
+// matchRadius pixels. A legend is drawn in the top left corner.
+//
+// The crop is computed from all pins of both sets.
+func MapDiff(worldMap image.Image, setA, setB []Coord, matchRadius int, opt *Options) image.Image {
+	coords := make([]Coord, 0, len(setA)+len(setB))
+	coords = append(coords, setA...)
+	coords = append(coords, setB...)
+	// Match pins on the unscaled map, which matchRadius is relative to.
+	cs, _ := layoutPoints(worldMap, coords, opt)
+	as, bs := cs[:len(setA)], cs[len(setA):]
+
+	parts := func(c color.Color) []image.Image {
+		pin := DefaultPin()
+		return []image.Image{pin[0], colorize(pin[1], c)}
+	}
+	added, removed, unchanged := parts(DiffAddedColor), parts(DiffRemovedColor), parts(DiffUnchangedColor)
+
+	// Pins of setA that are in setB have no parts and are not drawn.
+	pinParts := make([][]image.Image, len(coords))
+	for i, b := range bs {
+		if hasPointNear(as, b, matchRadius) {
+			pinParts[len(setA)+i] = unchanged
+		} else {
+			pinParts[len(setA)+i] = added
+		}
+	}
+	for i, a := range as {
+		if !hasPointNear(bs, a, matchRadius) {
+			pinParts[i] = removed
+		}
+	}
+
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		coords: coords,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts[i]}
+		},
+		above: func(m *image.RGBA, cs []image.Point, l layout) {
+			legend := RenderLegend([]LegendEntry{
+				{Color: DiffAddedColor, Label: "Added"},
+				{Color: DiffRemovedColor, Label: "Removed"},
+				{Color: DiffUnchangedColor, Label: "Unchanged"},
+			}, &LegendOption{Background: color.RGBA{0xff, 0xff, 0xff, 0xc0}})
+			margin := int(math.Round(l.px(8)))
+			lr := legend.Bounds().Add(m.Rect.Min).Add(image.Point{margin, margin})
+			draw.Draw(m, lr, legend, image.Point{}, draw.Over)
+		},
+	})
+	return finish(m, r, opt)
+}
+
+// hasPointNear reports whether any of points is within radius from p.
+func hasPointNear(points []image.Point, p image.Point, radius int) bool {
+	for _, q := range points {
+		dx, dy := p.X-q.X, p.Y-q.Y
+		if dx*dx+dy*dy <= radius*radius {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+// hasColorNear reports whether the image has a pixel close to the color
+// in the rectangle of the pin drawn at p.
+func hasColorNear(m image.Image, p image.Point, c color.Color) bool {
+	r1, g1, b1, _ := c.RGBA()
+	for y := p.Y - 37; y < p.Y; y++ {
+		for x := p.X - 32; x < p.X+32; x++ {
+			r2, g2, b2, _ := m.At(x, y).RGBA()
+			d := abs(int(r1>>8)-int(r2>>8)) + abs(int(g1>>8)-int(g2>>8)) + abs(int(b1>>8)-int(b2>>8))
+			if d < 30 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestMapDiff(t *testing.T) {
+	worldMap := solidMap(1000, 1000, color.White)
+	setA := []onmap.Coord{{0, 0}, {30, -60}}
+	setB := []onmap.Coord{{0.1, 0.1}, {-30, 60}}
+	m := onmap.MapDiff(worldMap, setA, setB, 5, nil)
+
+	tests := []struct {
+		name     string
+		coord    onmap.Coord
+		expected color.Color
+	}{
+		{"unchanged", setB[0], onmap.DiffUnchangedColor},
+		{"removed", setA[1], onmap.DiffRemovedColor},
+		{"added", setB[1], onmap.DiffAddedColor},
+	}
+	for _, tt := range tests {
+		p := onmap.Mercator.Convert(tt.coord, 1000, 1000)
+		for _, other := range tests {
+			found := hasColorNear(m, p, other.expected)
+			if other.name == tt.name && !found {
+				t.Errorf("%s: expected pin of color %v at %v", tt.name, other.expected, p)
+			}
+			if other.name != tt.name && found {
+				t.Errorf("%s: unexpected pin of color %v at %v", tt.name, other.expected, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package onmap
+
+import (
+	"fmt"
+	"math"
+)
+
+// earthRadius is the mean radius of Earth in meters.
+const earthRadius = 6371000
+
+// Unit is a unit of distance for Distance and for converting and
+// formatting distances in meters. It's only a conversion helper:
+// other functions, such as MapAccuracy, accept distances in meters,
+// which can be converted from other units with ToMeters.
+type Unit int
+
+const (
+	Kilometers Unit = iota
+	Miles
+	NauticalMiles
+)
+
+// meters returns the number of meters in one unit.
+func (u Unit) meters() float64 {
+	switch u {
+	case Miles:
+		return 1609.344
+	case NauticalMiles:
+		return 1852
+	default:
+		return 1000
+	}
+}
+
+// FromMeters converts the distance in meters to this unit.
+func (u Unit) FromMeters(m float64) float64 {
+	return m / u.meters()
+}
+
+// ToMeters converts the distance in this unit to meters.
+func (u Unit) ToMeters(d float64) float64 {
+	return d * u.meters()
+}
+
+// Suffix returns the abbreviation of the unit used in labels.
+func (u Unit) Suffix() string {
+	switch u {
+	case Miles:
+		return "mi"
+	case NauticalMiles:
+		return "nmi"
+	default:
+		return "km"
+	}
+}
+
+// Format returns the label for the distance in meters
+// converted to this unit, for example, "12.5 km".
+func (u Unit) Format(m float64) string {
+	d := u.FromMeters(m)
+	if d >= 100 || d == math.Trunc(d) {
+		return fmt.Sprintf("%.0f %s", d, u.Suffix())
+	}
+	return fmt.Sprintf("%.1f %s", d, u.Suffix())
+}
+
+// Distance returns the great-circle distance between
+// two coordinates in the given unit.
+func Distance(a, b Coord, u Unit) float64 {
+	return u.FromMeters(haversine(a, b))
+}
+
+// DistanceTo returns the great-circle distance
+// from c to the other coordinate in meters.
+func (c Coord) DistanceTo(other Coord) float64 {
+	return haversine(c, other)
+}
+
+// haversine returns the great-circle distance between
+// two coordinates in meters using the haversine formula.
+func haversine(a, b Coord) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLong := (b.Long - a.Long) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
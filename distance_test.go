@@ -0,0 +1,51 @@
+package onmap_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestDistanceUnits(t *testing.T) {
+	moscow := onmap.Coord{55.755833, 37.617222}
+	rome := onmap.Coord{41.9097306, 12.2558141}
+	tests := []struct {
+		unit     onmap.Unit
+		distance float64
+		suffix   string
+	}{
+		{onmap.Kilometers, 2388, "km"},
+		{onmap.Miles, 1484, "mi"},
+		{onmap.NauticalMiles, 1289, "nmi"},
+	}
+	for _, tt := range tests {
+		d := onmap.Distance(moscow, rome, tt.unit)
+		if math.Abs(d-tt.distance) > 1 {
+			t.Errorf("%s: expected distance about %v, got %v", tt.suffix, tt.distance, d)
+		}
+		if s := tt.unit.Suffix(); s != tt.suffix {
+			t.Errorf("expected suffix %q, got %q", tt.suffix, s)
+		}
+		if m := tt.unit.ToMeters(d); math.Abs(m-onmap.Distance(moscow, rome, onmap.Kilometers)*1000) > 1e-6 {
+			t.Errorf("%s: round trip to meters failed: %v", tt.suffix, m)
+		}
+	}
+	if s := onmap.Miles.Format(1609.344 * 12.5); s != "12.5 mi" {
+		t.Errorf("expected label %q, got %q", "12.5 mi", s)
+	}
+}
+
+func TestDistanceTo(t *testing.T) {
+	moscow := onmap.Coord{55.755833, 37.617222}
+	rome := onmap.Coord{41.9097306, 12.2558141}
+	if d := moscow.DistanceTo(rome); math.Abs(d-2388e3) > 1e3 {
+		t.Errorf("expected distance about 2388 km, got %v m", d)
+	}
+	if d, r := moscow.DistanceTo(rome), rome.DistanceTo(moscow); d != r {
+		t.Errorf("expected symmetric distance, got %v and %v", d, r)
+	}
+	if d := rome.DistanceTo(rome); d != 0 {
+		t.Errorf("expected zero distance, got %v", d)
+	}
+}
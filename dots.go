@@ -0,0 +1,52 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+)
+
+// MapDots returns an image with the given coordinates marked as dots:
+// circles of the given radius in pixels centered on each point, filled
+// with the fill color and outlined with the stroke color, if it's not
+// nil. If crop is nil, doesn't crop the image; otherwise its bound is
+// extended to fit whole dots.
+//
+// World map must be in the given projection.
+func MapDots(proj Projection, worldMap image.Image, coords []Coord, radius int, fill, stroke color.Color, crop *CropOption) image.Image {
+	if crop != nil {
+		c := *crop
+		c.Bound += radius + 1
+		for _, b := range []*int{&c.BoundTop, &c.BoundBottom, &c.BoundLeft, &c.BoundRight} {
+			if *b != 0 {
+				*b += radius + 1
+			}
+		}
+		crop = &c
+	}
+	parts := []image.Image{dotImage(radius, fill, stroke)}
+	opt := &Options{
+		Projection: proj,
+		Crop:       crop,
+		Anchors:    []Anchor{{Tip: image.Point{radius, radius}}},
+	}
+	return Render(worldMap, parts, coords, opt)
+}
+
+// dotStrokeWidth is the width of the dot outline in pixels.
+const dotStrokeWidth = 2
+
+// dotImage returns an image of the dot of the given radius
+// centered on the pixel at (radius, radius).
+func dotImage(radius int, fill, stroke color.Color) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, 2*radius+1, 2*radius+1))
+	cx, cy := pixelCenter(image.Point{radius, radius})
+	r := float64(radius)
+	if stroke != nil {
+		fillCircle(m, cx, cy, r, stroke)
+		r -= dotStrokeWidth
+	}
+	if r > 0 {
+		fillCircle(m, cx, cy, r, fill)
+	}
+	return m
+}
@@ -0,0 +1,42 @@
+package onmap_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapDots(t *testing.T) {
+	worldMap := solidMap(2000, 2000, color.White)
+	coords := []onmap.Coord{
+		{51.5, -0.1},  // London
+		{48.9, 2.4},   // Paris
+		{40.7, -74.0}, // New York
+	}
+	fill := color.RGBA{0xff, 0, 0, 0xff}
+	stroke := color.RGBA{0, 0, 0, 0xff}
+	m := onmap.MapDots(onmap.Mercator, worldMap, coords, 6, fill, stroke, nil)
+	for _, c := range coords {
+		p := onmap.Mercator.Convert(c, 2000, 2000)
+		if got := m.At(p.X, p.Y); !sameColor(got, fill) {
+			t.Errorf("%v: expected fill at the center, got %v", c, got)
+		}
+		if got := m.At(p.X, p.Y-5); !sameColor(got, stroke) {
+			t.Errorf("%v: expected stroke near the edge, got %v", c, got)
+		}
+		if got := m.At(p.X, p.Y+7); !sameColor(got, color.White) {
+			t.Errorf("%v: expected map outside of the dot, got %v", c, got)
+		}
+	}
+
+	// The crop fits whole dots.
+	crop := &onmap.CropOption{Bound: 0}
+	m = onmap.MapDots(onmap.Mercator, worldMap, coords[:1], 6, fill, nil, crop)
+	if s := m.Bounds().Size(); s.X != 14 || s.Y != 14 {
+		t.Errorf("expected 14x14 crop, got %v", s)
+	}
+	if n := countColor(m, fill); n == 0 {
+		t.Errorf("expected dot in the crop")
+	}
+}
@@ -0,0 +1,60 @@
+package onmap
+
+import (
+	"image"
+	"math"
+)
+
+// EmphasisMode defines how pins are emphasized.
+type EmphasisMode int
+
+const (
+	// EmphasizeSizeAndAlpha changes both size and opacity of pins.
+	EmphasizeSizeAndAlpha EmphasisMode = iota
+
+	// EmphasizeSize changes only size of pins.
+	EmphasizeSize
+
+	// EmphasizeAlpha changes only opacity of pins.
+	EmphasizeAlpha
+)
+
+// EmphasisOption defines options for emphasizing pins
+// near the center of the image.
+type EmphasisOption struct {
+	// Falloff is the amount by which size and opacity of pins decrease
+	// from the center to the corners of the crop rectangle, from 0
+	// (no change) to 1 (pins in the corners disappear).
+	Falloff float64
+
+	// Mode defines whether size, opacity, or both are changed.
+	Mode EmphasisMode
+}
+
+// apply replaces pin parts of markers with emphasized ones
+// according to their distance from the center of r.
+func (e *EmphasisOption) apply(markers []marker, r image.Rectangle) {
+	cx, cy := float64(r.Min.X+r.Max.X)/2, float64(r.Min.Y+r.Max.Y)/2
+	maxDist := math.Hypot(float64(r.Dx()), float64(r.Dy())) / 2
+	if maxDist == 0 {
+		return
+	}
+	for i, mk := range markers {
+		d := math.Min(1, math.Hypot(float64(mk.pt.X)-cx, float64(mk.pt.Y)-cy)/maxDist)
+		k := math.Max(0, 1-e.Falloff*d)
+		if k == 1 {
+			continue
+		}
+		parts := make([]image.Image, len(mk.parts))
+		for j, p := range mk.parts {
+			if e.Mode != EmphasizeAlpha {
+				p = scaleBy(p, k)
+			}
+			if e.Mode != EmphasizeSize {
+				p = fade(p, k)
+			}
+			parts[j] = p
+		}
+		markers[i].parts = parts
+	}
+}
@@ -0,0 +1,48 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderCenterEmphasis(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{0, 0}, {0, 150}}
+	opt := &onmap.Options{CenterEmphasis: &onmap.EmphasisOption{Falloff: 0.8}}
+	m := onmap.Render(worldMap, onmap.DefaultPin(), coords, opt).(*image.RGBA)
+
+	pinArea := func(p image.Point) image.Image {
+		return m.SubImage(image.Rect(p.X-40, p.Y-50, p.X+40, p.Y+1))
+	}
+	center := pinArea(onmap.Mercator.Convert(coords[0], 360, 360))
+	edge := pinArea(onmap.Mercator.Convert(coords[1], 360, 360))
+	cn, en := countNotColor(center, color.White), countNotColor(edge, color.White)
+	if cn <= en {
+		t.Errorf("expected central pin to be larger: %d vs %d pixels", cn, en)
+	}
+
+	// Opacity only: same area, but peripheral pin is lighter.
+	opt.CenterEmphasis.Mode = onmap.EmphasizeAlpha
+	m = onmap.Render(worldMap, onmap.DefaultPin(), coords, opt).(*image.RGBA)
+	center = pinArea(onmap.Mercator.Convert(coords[0], 360, 360))
+	edge = pinArea(onmap.Mercator.Convert(coords[1], 360, 360))
+	if darkness(center) <= darkness(edge) {
+		t.Errorf("expected central pin to be more opaque")
+	}
+}
+
+// darkness returns the sum of differences of pixels from white.
+func darkness(m image.Image) int {
+	n := 0
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, b, _ := m.At(x, y).RGBA()
+			n += 3*0xffff - int(r+g+b)
+		}
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package onmap
+
+import (
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// EncodePins renders the coordinates with Pins and writes the image
+// to w in the given format, which is either "png" or "jpeg".
+func EncodePins(w io.Writer, coords []Coord, crop *CropOption, format string) error {
+	switch format {
+	case "png":
+		return png.Encode(w, Pins(coords, crop))
+	case "jpeg", "jpg":
+		return jpeg.Encode(w, Pins(coords, crop), nil)
+	default:
+		return fmt.Errorf("onmap: unknown image format %q", format)
+	}
+}
+
+// EncodePinsJPEG renders the coordinates like Pins and writes the image
+// to w as JPEG with the given quality from 1 to 100. Since JPEG has no
+// alpha channel, the image is flattened over the background color first.
+// If bg is nil, white is used.
+func EncodePinsJPEG(w io.Writer, coords []Coord, crop *CropOption, quality int, bg color.Color) error {
+	if quality < 1 || quality > 100 {
+		return fmt.Errorf("onmap: JPEG quality %d is outside of the range 1-100", quality)
+	}
+	m := Render(DefaultMap(), DefaultPin(), coords, &Options{
+		Crop:       crop,
+		Background: colorOr(bg, color.White),
+	})
+	return jpeg.Encode(w, m, &jpeg.Options{Quality: quality})
+}
@@ -0,0 +1,54 @@
+package onmap_test
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestEncodePins(t *testing.T) {
+	coords := []onmap.Coord{{42.1, 19.1}} // Bar
+	size := onmap.Pins(coords, onmap.StandardCrop).Bounds().Size()
+	for _, format := range []string{"png", "jpeg"} {
+		var buf bytes.Buffer
+		if err := onmap.EncodePins(&buf, coords, onmap.StandardCrop, format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		m, name, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if name != format {
+			t.Errorf("expected %s, decoded %s", format, name)
+		}
+		if m.Bounds().Size() != size {
+			t.Errorf("%s: expected %v image size, got %v", format, size, m.Bounds().Size())
+		}
+	}
+	if err := onmap.EncodePins(&bytes.Buffer{}, coords, nil, "bmp"); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
+
+func TestEncodePinsJPEG(t *testing.T) {
+	coords := []onmap.Coord{{42.1, 19.1}} // Bar
+	sizes := make(map[int]int)
+	for _, quality := range []int{50, 90} {
+		var buf bytes.Buffer
+		if err := onmap.EncodePinsJPEG(&buf, coords, onmap.StandardCrop, quality, nil); err != nil {
+			t.Fatalf("quality %d: %v", quality, err)
+		}
+		sizes[quality] = buf.Len()
+		if _, name, err := image.Decode(&buf); err != nil || name != "jpeg" {
+			t.Fatalf("quality %d: expected JPEG, got %q, %v", quality, name, err)
+		}
+	}
+	if sizes[50] >= sizes[90] {
+		t.Errorf("expected quality 50 to be smaller than 90, got %d and %d bytes", sizes[50], sizes[90])
+	}
+	if err := onmap.EncodePinsJPEG(&bytes.Buffer{}, coords, nil, 0, nil); err == nil {
+		t.Errorf("expected error for quality 0")
+	}
+}
@@ -0,0 +1,72 @@
+package onmap
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// Errors returned by functions that validate their input.
+// They may be wrapped with details, use errors.Is to check them.
+var (
+	// ErrEmptyCoords is returned when cropping is requested for no coordinates.
+	ErrEmptyCoords = errors.New("onmap: no coordinates to crop around")
+
+	// ErrInvalidCrop is returned for crop options with invalid values.
+	ErrInvalidCrop = errors.New("onmap: invalid crop option")
+
+	// ErrMapNotCroppable is returned when the world map
+	// is too small to be cropped with the given crop options.
+	ErrMapNotCroppable = errors.New("onmap: map is not croppable")
+
+	// ErrInvalidCoord is returned for coordinates outside of
+	// the valid latitude and longitude ranges.
+	ErrInvalidCoord = errors.New("onmap: invalid coordinate")
+)
+
+// Validate checks that the world map, coordinates and options
+// are suitable for rendering, returning one of the package errors
+// (possibly wrapped) if they are not.
+func Validate(worldMap image.Image, coords []Coord, opt *Options) error {
+	for i, c := range coords {
+		if !c.Valid() {
+			return fmt.Errorf("%w: %v at index %d", ErrInvalidCoord, c, i)
+		}
+	}
+	crop := opt.crop()
+	if crop == nil || crop.FixedBounds != nil {
+		return nil
+	}
+	if len(coords) == 0 {
+		return ErrEmptyCoords
+	}
+	if crop.Bound < 0 || crop.BoundTop < 0 || crop.BoundBottom < 0 || crop.BoundLeft < 0 || crop.BoundRight < 0 ||
+		crop.MinWidth < 0 || crop.MinHeight < 0 || crop.MaxWidth < 0 || crop.MaxHeight < 0 ||
+		crop.MaxZoomPixelsPerDegree < 0 {
+		return fmt.Errorf("%w: negative value", ErrInvalidCrop)
+	}
+	if (crop.MaxWidth > 0 && crop.MaxWidth < crop.MinWidth) || (crop.MaxHeight > 0 && crop.MaxHeight < crop.MinHeight) {
+		return fmt.Errorf("%w: maximum size is smaller than the minimum size", ErrInvalidCrop)
+	}
+	if crop.PreserveRatio && crop.MinWidth == 0 {
+		return fmt.Errorf("%w: zero MinWidth with PreserveRatio", ErrInvalidCrop)
+	}
+	b := worldMap.Bounds()
+	if opt.Canvas != nil {
+		b = image.Rect(0, 0, opt.Canvas.Width, opt.Canvas.Height)
+	}
+	if b.Dx() < crop.MinWidth || b.Dy() < crop.MinHeight {
+		return fmt.Errorf("%w: %dx%d map is smaller than the minimum crop size %dx%d",
+			ErrMapNotCroppable, b.Dx(), b.Dy(), crop.MinWidth, crop.MinHeight)
+	}
+	return nil
+}
+
+// RenderChecked is like Render, but validates its input first
+// and returns an error instead of rendering if it's invalid.
+func RenderChecked(worldMap image.Image, pinParts []image.Image, coords []Coord, opt *Options) (image.Image, error) {
+	if err := Validate(worldMap, coords, opt); err != nil {
+		return nil, err
+	}
+	return Render(worldMap, pinParts, coords, opt), nil
+}
@@ -0,0 +1,56 @@
+package onmap_test
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderCheckedErrors(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{42.1, 19.1}}
+	tests := []struct {
+		name   string
+		coords []onmap.Coord
+		opt    *onmap.Options
+		err    error
+	}{
+		{"valid", coords, &onmap.Options{Crop: &onmap.CropOption{Bound: 10}}, nil},
+		{"empty coords", nil, &onmap.Options{Crop: onmap.StandardCrop}, onmap.ErrEmptyCoords},
+		{"invalid crop", coords, &onmap.Options{Crop: &onmap.CropOption{Bound: -1}}, onmap.ErrInvalidCrop},
+		{"negative min width", coords, &onmap.Options{Crop: &onmap.CropOption{MinWidth: -10}}, onmap.ErrInvalidCrop},
+		{"max width below min width", coords, &onmap.Options{Crop: &onmap.CropOption{MinWidth: 100, MaxWidth: 50}}, onmap.ErrInvalidCrop},
+		{"zero min width with ratio", coords, &onmap.Options{Crop: &onmap.CropOption{MinHeight: 10, PreserveRatio: true}}, onmap.ErrInvalidCrop},
+		{"not croppable", coords, &onmap.Options{Crop: onmap.StandardCrop}, onmap.ErrMapNotCroppable},
+		{"invalid coord", []onmap.Coord{{200, 500}}, nil, onmap.ErrInvalidCoord},
+	}
+	for _, tt := range tests {
+		m, err := onmap.RenderChecked(worldMap, onmap.DefaultPin(), tt.coords, tt.opt)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.err, err)
+		}
+		if (err == nil) != (m != nil) {
+			t.Errorf("%s: expected image only without error", tt.name)
+		}
+	}
+}
+
+func TestRenderInvalidCrop(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{42.1, 19.1}}
+	for _, crop := range []*onmap.CropOption{
+		{Bound: -50},
+		{MinWidth: 0, MinHeight: 10, PreserveRatio: true},
+		{Bound: 10, MinWidth: -100, MinHeight: -100},
+		{MinWidth: 100, MinHeight: 200, PreserveRatio: true},
+	} {
+		// Invalid values are clamped instead of producing broken crops.
+		m := onmap.Render(worldMap, nil, coords, &onmap.Options{Crop: crop})
+		b := m.Bounds()
+		if b.Empty() || !b.In(worldMap.Bounds()) {
+			t.Errorf("%+v: expected non-empty crop inside of the map, got %v", *crop, b)
+		}
+	}
+}
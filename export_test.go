@@ -0,0 +1,14 @@
+package onmap
+
+// Exported for tests.
+var (
+	SubImage    = subImage
+	PlaceLabels = placeLabels
+)
+
+// CachedBases returns the number of maps cached by BaseAtWidth.
+func CachedBases() int {
+	basesMu.Lock()
+	defer basesMu.Unlock()
+	return len(bases)
+}
@@ -0,0 +1,233 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Dimensions of glyphs of the built-in font in pixels.
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+)
+
+// textSize returns the size of the text drawn
+// with the built-in font at the given scale.
+func textSize(s string, scale int) image.Point {
+	n := len([]rune(s))
+	if n == 0 {
+		return image.Point{}
+	}
+	return image.Point{(n*glyphAdvance - 1) * scale, glyphHeight * scale}
+}
+
+// textMask returns the alpha mask of the text drawn with the built-in
+// font at the given scale. Characters missing in the font are drawn as '?'.
+func textMask(s string, scale int) *image.Alpha {
+	m := image.NewAlpha(image.Rectangle{Max: textSize(s, scale)})
+	i := 0
+	for _, r := range s {
+		if r < ' ' || r > '~' {
+			r = '?'
+		}
+		g := glyphs[r-' ']
+		for y, bits := range g {
+			for x := 0; x < glyphWidth; x++ {
+				if bits&(1<<(glyphWidth-1-x)) == 0 {
+					continue
+				}
+				px := (i*glyphAdvance + x) * scale
+				py := y * scale
+				draw.Draw(m, image.Rect(px, py, px+scale, py+scale), image.Opaque, image.Point{}, draw.Src)
+			}
+		}
+		i++
+	}
+	return m
+}
+
+// drawText draws the text with its top-left corner at p.
+func drawText(dst draw.Image, p image.Point, s string, scale int, c color.Color) {
+	mask := textMask(s, scale)
+	r := mask.Bounds().Add(p)
+	draw.DrawMask(dst, r, image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// Font is a source of glyph images for text drawn on maps.
+type Font interface {
+	// Glyph returns the image of the glyph for the rune, including
+	// spacing after it. Alpha images are used as masks and drawn with
+	// the text color, other images, such as color emoji, are drawn as is.
+	// It returns false if the font has no glyph for the rune.
+	Glyph(r rune) (image.Image, bool)
+}
+
+// builtinFont is the built-in 5x7 pixel font with the given scale.
+type builtinFont int
+
+// Glyph implements Font.
+func (f builtinFont) Glyph(r rune) (image.Image, bool) {
+	if r < ' ' || r > '~' {
+		return nil, false
+	}
+	scale := int(f)
+	m := image.NewAlpha(image.Rect(0, 0, glyphAdvance*scale, glyphHeight*scale))
+	for y, bits := range glyphs[r-' '] {
+		for x := 0; x < glyphWidth; x++ {
+			if bits&(1<<(glyphWidth-1-x)) != 0 {
+				draw.Draw(m, image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale), image.Opaque, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return m, true
+}
+
+// drawFontText draws the text with the font with its top-left corner
+// at p, skipping runes missing in the font, and returns the rectangle
+// occupied by the text.
+func drawFontText(dst draw.Image, f Font, p image.Point, s string, c color.Color) image.Rectangle {
+	r := image.Rectangle{p, p}
+	for _, ch := range s {
+		g, ok := f.Glyph(ch)
+		if !ok {
+			continue
+		}
+		gb := g.Bounds()
+		gr := image.Rectangle{Max: gb.Size()}.Add(image.Point{r.Max.X, p.Y})
+		if dst != nil {
+			if _, isMask := g.(*image.Alpha); isMask {
+				draw.DrawMask(dst, gr, image.NewUniform(c), image.Point{}, g, gb.Min, draw.Over)
+			} else {
+				draw.Draw(dst, gr, g, gb.Min, draw.Over)
+			}
+		}
+		r = r.Union(gr)
+	}
+	return r
+}
+
+// fontTextSize returns the size of the text drawn with the font.
+func fontTextSize(f Font, s string) image.Point {
+	return drawFontText(nil, f, image.Point{}, s, nil).Size()
+}
+
+// measureText returns the size of the text drawn with the font
+// or, if it's nil, with the built-in font at the given scale.
+func measureText(f Font, s string, scale int) image.Point {
+	if f == nil {
+		return textSize(s, scale)
+	}
+	return fontTextSize(f, s)
+}
+
+// drawTextWith draws the text with the font or, if it's nil,
+// with the built-in font at the given scale with its top-left corner at p.
+func drawTextWith(dst draw.Image, f Font, p image.Point, s string, scale int, c color.Color) {
+	if f == nil {
+		drawText(dst, p, s, scale, c)
+		return
+	}
+	drawFontText(dst, f, p, s, c)
+}
+
+// glyphs is a 5x7 pixel font for ASCII characters from ' ' to '~'.
+// Each byte is a row of a glyph with bit 4 as the leftmost pixel.
+var glyphs = [...][glyphHeight]uint8{
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ' '
+	{0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04}, // '!'
+	{0x0a, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00}, // '"'
+	{0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a}, // '#'
+	{0x04, 0x0f, 0x14, 0x0e, 0x05, 0x1e, 0x04}, // '$'
+	{0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03}, // '%'
+	{0x0c, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0d}, // '&'
+	{0x04, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00}, // '\''
+	{0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02}, // '('
+	{0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08}, // ')'
+	{0x00, 0x04, 0x15, 0x0e, 0x15, 0x04, 0x00}, // '*'
+	{0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00}, // '+'
+	{0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08}, // ','
+	{0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00}, // '-'
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c}, // '.'
+	{0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00}, // '/'
+	{0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e}, // '0'
+	{0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e}, // '1'
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f}, // '2'
+	{0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e}, // '3'
+	{0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02}, // '4'
+	{0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e}, // '5'
+	{0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e}, // '6'
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08}, // '7'
+	{0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e}, // '8'
+	{0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c}, // '9'
+	{0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00}, // ':'
+	{0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x04, 0x08}, // ';'
+	{0x02, 0x04, 0x08, 0x10, 0x08, 0x04, 0x02}, // '<'
+	{0x00, 0x00, 0x1f, 0x00, 0x1f, 0x00, 0x00}, // '='
+	{0x08, 0x04, 0x02, 0x01, 0x02, 0x04, 0x08}, // '>'
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04}, // '?'
+	{0x0e, 0x11, 0x01, 0x0d, 0x15, 0x15, 0x0e}, // '@'
+	{0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}, // 'A'
+	{0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e}, // 'B'
+	{0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e}, // 'C'
+	{0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c}, // 'D'
+	{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f}, // 'E'
+	{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10}, // 'F'
+	{0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f}, // 'G'
+	{0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}, // 'H'
+	{0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}, // 'I'
+	{0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c}, // 'J'
+	{0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11}, // 'K'
+	{0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f}, // 'L'
+	{0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11}, // 'M'
+	{0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11}, // 'N'
+	{0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}, // 'O'
+	{0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10}, // 'P'
+	{0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d}, // 'Q'
+	{0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11}, // 'R'
+	{0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e}, // 'S'
+	{0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, // 'T'
+	{0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}, // 'U'
+	{0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04}, // 'V'
+	{0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a}, // 'W'
+	{0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11}, // 'X'
+	{0x11, 0x11, 0x0a, 0x04, 0x04, 0x04, 0x04}, // 'Y'
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f}, // 'Z'
+	{0x0e, 0x08, 0x08, 0x08, 0x08, 0x08, 0x0e}, // '['
+	{0x00, 0x10, 0x08, 0x04, 0x02, 0x01, 0x00}, // '\\'
+	{0x0e, 0x02, 0x02, 0x02, 0x02, 0x02, 0x0e}, // ']'
+	{0x04, 0x0a, 0x11, 0x00, 0x00, 0x00, 0x00}, // '^'
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f}, // '_'
+	{0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00}, // '`'
+	{0x00, 0x00, 0x0e, 0x01, 0x0f, 0x11, 0x0f}, // 'a'
+	{0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x1e}, // 'b'
+	{0x00, 0x00, 0x0e, 0x10, 0x10, 0x11, 0x0e}, // 'c'
+	{0x01, 0x01, 0x0d, 0x13, 0x11, 0x11, 0x0f}, // 'd'
+	{0x00, 0x00, 0x0e, 0x11, 0x1f, 0x10, 0x0e}, // 'e'
+	{0x06, 0x09, 0x08, 0x1c, 0x08, 0x08, 0x08}, // 'f'
+	{0x00, 0x0f, 0x11, 0x11, 0x0f, 0x01, 0x0e}, // 'g'
+	{0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x11}, // 'h'
+	{0x04, 0x00, 0x0c, 0x04, 0x04, 0x04, 0x0e}, // 'i'
+	{0x02, 0x00, 0x06, 0x02, 0x02, 0x12, 0x0c}, // 'j'
+	{0x10, 0x10, 0x12, 0x14, 0x18, 0x14, 0x12}, // 'k'
+	{0x0c, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}, // 'l'
+	{0x00, 0x00, 0x1a, 0x15, 0x15, 0x11, 0x11}, // 'm'
+	{0x00, 0x00, 0x16, 0x19, 0x11, 0x11, 0x11}, // 'n'
+	{0x00, 0x00, 0x0e, 0x11, 0x11, 0x11, 0x0e}, // 'o'
+	{0x00, 0x00, 0x1e, 0x11, 0x1e, 0x10, 0x10}, // 'p'
+	{0x00, 0x00, 0x0d, 0x13, 0x0f, 0x01, 0x01}, // 'q'
+	{0x00, 0x00, 0x16, 0x19, 0x10, 0x10, 0x10}, // 'r'
+	{0x00, 0x00, 0x0e, 0x10, 0x0e, 0x01, 0x1e}, // 's'
+	{0x08, 0x08, 0x1c, 0x08, 0x08, 0x09, 0x06}, // 't'
+	{0x00, 0x00, 0x11, 0x11, 0x11, 0x13, 0x0d}, // 'u'
+	{0x00, 0x00, 0x11, 0x11, 0x11, 0x0a, 0x04}, // 'v'
+	{0x00, 0x00, 0x11, 0x11, 0x15, 0x15, 0x0a}, // 'w'
+	{0x00, 0x00, 0x11, 0x0a, 0x04, 0x0a, 0x11}, // 'x'
+	{0x00, 0x00, 0x11, 0x11, 0x0f, 0x01, 0x0e}, // 'y'
+	{0x00, 0x00, 0x1f, 0x02, 0x04, 0x08, 0x1f}, // 'z'
+	{0x02, 0x04, 0x04, 0x08, 0x04, 0x04, 0x02}, // '{'
+	{0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, // '|'
+	{0x08, 0x04, 0x04, 0x02, 0x04, 0x04, 0x08}, // '}'
+	{0x00, 0x00, 0x08, 0x15, 0x02, 0x00, 0x00}, // '~'
+}
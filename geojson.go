@@ -0,0 +1,62 @@
+package onmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONDocument struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry *geoJSONGeometry `json:"geometry"`
+	} `json:"features"`
+}
+
+// CoordsFromGeoJSON reads a GeoJSON FeatureCollection and returns
+// coordinates of all Point and MultiPoint geometries of its features
+// in the order of their appearance. Other geometries are ignored.
+func CoordsFromGeoJSON(r io.Reader) ([]Coord, error) {
+	var doc geoJSONDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("onmap: GeoJSON type %q is not FeatureCollection", doc.Type)
+	}
+	var coords []Coord
+	for _, f := range doc.Features {
+		g := f.Geometry
+		if g == nil {
+			continue
+		}
+		var positions [][]float64
+		switch g.Type {
+		case "Point":
+			var p []float64
+			if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+				return nil, err
+			}
+			positions = [][]float64{p}
+		case "MultiPoint":
+			if err := json.Unmarshal(g.Coordinates, &positions); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		for _, p := range positions {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("onmap: GeoJSON position %v has less than two elements", p)
+			}
+			// GeoJSON positions are longitude first.
+			coords = append(coords, Coord{Lat: p[1], Long: p[0]})
+		}
+	}
+	return coords, nil
+}
@@ -0,0 +1,54 @@
+package onmap_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+const testGeoJSON = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "geometry": {"type": "Point", "coordinates": [19.262892, 42.441286]},
+      "properties": {"name": "Podgorica"}
+    },
+    {
+      "type": "Feature",
+      "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]},
+      "properties": {}
+    },
+    {
+      "type": "Feature",
+      "geometry": {"type": "MultiPoint", "coordinates": [[19.1, 42.1, 5], [12.2558141, 41.9097306]]},
+      "properties": {}
+    },
+    {
+      "type": "Feature",
+      "geometry": null,
+      "properties": {}
+    }
+  ]
+}`
+
+func TestCoordsFromGeoJSON(t *testing.T) {
+	coords, err := onmap.CoordsFromGeoJSON(strings.NewReader(testGeoJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []onmap.Coord{
+		{42.441286, 19.262892},   // Podgorica
+		{42.1, 19.1},             // Bar
+		{41.9097306, 12.2558141}, // Rome
+	}
+	if !reflect.DeepEqual(coords, expected) {
+		t.Errorf("expected %v, got %v", expected, coords)
+	}
+
+	if _, err := onmap.CoordsFromGeoJSON(strings.NewReader(`{"type": "Point", "coordinates": [0, 0]}`)); err == nil {
+		t.Errorf("expected error for non-FeatureCollection document")
+	}
+}
@@ -0,0 +1,74 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+)
+
+// GlyphMarker is a glyph, such as an emoji or a symbol, drawn at the coordinate.
+type GlyphMarker struct {
+	Coord
+
+	// Glyph is the text of the marker.
+	Glyph string
+}
+
+// GlyphOption defines options for MapGlyphs.
+type GlyphOption struct {
+	// Font is the font of glyphs. To draw emoji, supply a font
+	// that has them. If nil, the built-in font is used,
+	// which only has ASCII characters.
+	Font Font
+
+	// Color is the color of glyphs that are alpha masks.
+	// If nil, black is used.
+	Color color.Color
+
+	// Bottom, if true, anchors glyphs at their bottom center
+	// instead of the center.
+	Bottom bool
+}
+
+// MapGlyphs is like Render, but draws glyph markers instead of pins.
+// The crop is computed to include whole glyphs.
+// If gopt is nil, uses the default options.
+func MapGlyphs(worldMap image.Image, items []GlyphMarker, gopt *GlyphOption, opt *Options) image.Image {
+	if gopt == nil {
+		gopt = &GlyphOption{}
+	}
+	var f Font = builtinFont(2)
+	if gopt.Font != nil {
+		f = gopt.Font
+	}
+	coords := make([]Coord, len(items))
+	for i, it := range items {
+		coords[i] = it.Coord
+	}
+	// Glyphs are not scaled with the map, so they are drawn
+	// at their points on the image.
+	rects := make([]image.Rectangle, len(items))
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		extra: coords,
+		cropPoints: func(cs []image.Point) []image.Point {
+			var bounds []image.Point
+			for i, it := range items {
+				size := fontTextSize(f, it.Glyph)
+				min := cs[i].Sub(image.Point{size.X / 2, size.Y / 2})
+				if gopt.Bottom {
+					min = cs[i].Sub(image.Point{size.X / 2, size.Y})
+				}
+				rects[i] = image.Rectangle{min, min.Add(size)}
+				bounds = append(bounds, rects[i].Min, rects[i].Max)
+			}
+			return bounds
+		},
+		above: func(m *image.RGBA, cs []image.Point, l layout) {
+			c := colorOr(gopt.Color, color.Black)
+			for i, it := range items {
+				drawFontText(m, f, rects[i].Min, it.Glyph, c)
+			}
+		},
+	})
+	return finish(m, r, opt)
+}
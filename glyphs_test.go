@@ -0,0 +1,40 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+// anchorFont is a test font with a blue square for '⚓'.
+type anchorFont struct{}
+
+func (anchorFont) Glyph(r rune) (image.Image, bool) {
+	if r != '⚓' {
+		return nil, false
+	}
+	return solidMap(8, 8, color.RGBA{0, 0, 0xff, 0xff}), true
+}
+
+func TestMapGlyphs(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	items := []onmap.GlyphMarker{
+		{Coord: onmap.Coord{0, 0}, Glyph: "⚓"},
+		{Coord: onmap.Coord{20, 20}, Glyph: "*"},
+	}
+	m := onmap.MapGlyphs(worldMap, items, &onmap.GlyphOption{Font: anchorFont{}}, nil)
+	if c := m.At(180, 180); !sameColor(c, color.RGBA{0, 0, 0xff, 0xff}) {
+		t.Errorf("expected glyph at the projected point, got %v", c)
+	}
+
+	// Built-in font.
+	m = onmap.MapGlyphs(worldMap, items[1:], nil, &onmap.Options{Crop: &onmap.CropOption{}})
+	if countNotColor(m, color.White) == 0 {
+		t.Errorf("expected glyph drawn with the built-in font")
+	}
+	if b := m.Bounds(); b.Dx() < 10 || b.Dy() < 14 {
+		t.Errorf("expected crop to include the glyph, got %v", b)
+	}
+}
@@ -0,0 +1,43 @@
+package onmap
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Long float64 `xml:"lon,attr"`
+}
+
+type gpxDocument struct {
+	Waypoints []gpxPoint `xml:"wpt"`
+	Tracks    []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// CoordsFromGPX reads a GPX document and returns coordinates of its
+// waypoints and tracks. Each track segment is returned as a separate
+// track, preserving the order of points.
+func CoordsFromGPX(r io.Reader) (waypoints []Coord, tracks [][]Coord, err error) {
+	var doc gpxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+	for _, p := range doc.Waypoints {
+		waypoints = append(waypoints, Coord{p.Lat, p.Long})
+	}
+	for _, trk := range doc.Tracks {
+		for _, seg := range trk.Segments {
+			track := make([]Coord, len(seg.Points))
+			for i, p := range seg.Points {
+				track[i] = Coord{p.Lat, p.Long}
+			}
+			tracks = append(tracks, track)
+		}
+	}
+	return waypoints, tracks, nil
+}
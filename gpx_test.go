@@ -0,0 +1,36 @@
+package onmap_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="42.441286" lon="19.262892"><name>Podgorica</name></wpt>
+  <trk>
+    <name>Walk</name>
+    <trkseg>
+      <trkpt lat="42.1" lon="19.1"><ele>5</ele></trkpt>
+      <trkpt lat="42.2" lon="19.05"><ele>10</ele></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestCoordsFromGPX(t *testing.T) {
+	waypoints, tracks, err := onmap.CoordsFromGPX(strings.NewReader(testGPX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedWaypoints := []onmap.Coord{{42.441286, 19.262892}}
+	if !reflect.DeepEqual(waypoints, expectedWaypoints) {
+		t.Errorf("expected waypoints %v, got %v", expectedWaypoints, waypoints)
+	}
+	expectedTracks := [][]onmap.Coord{{{42.1, 19.1}, {42.2, 19.05}}}
+	if !reflect.DeepEqual(tracks, expectedTracks) {
+		t.Errorf("expected tracks %v, got %v", expectedTracks, tracks)
+	}
+}
@@ -0,0 +1,82 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// GraticuleOption defines the grid of latitude and longitude lines.
+type GraticuleOption struct {
+	// Spacing is the distance between lines in degrees.
+	// If zero, 30 is used.
+	Spacing float64
+
+	// Color is the color of lines. If nil, translucent white is used.
+	Color color.Color
+
+	// Width is the width of lines in pixels. If zero, 1 is used.
+	Width float64
+}
+
+// draw draws the graticule onto the image with the map layout.
+// Lines are sampled every degree and projected, so they
+// follow the projection of the map.
+func (g *GraticuleOption) draw(m *image.RGBA, l layout) {
+	spacing := g.Spacing
+	if spacing <= 0 {
+		spacing = 30
+	}
+	width := g.width()
+	c := colorOr(g.Color, color.NRGBA{0xff, 0xff, 0xff, 0x80})
+
+	var paths [][]Coord
+	for long := -180.0; long <= 180; long += spacing {
+		var path []Coord
+		for lat := -90.0; lat <= 90; lat++ {
+			path = append(path, Coord{lat, long})
+		}
+		paths = append(paths, path)
+	}
+	for lat := -math.Floor(90/spacing) * spacing; lat <= 90; lat += spacing {
+		var path []Coord
+		for long := -180.0; long <= 180; long++ {
+			path = append(path, Coord{lat, long})
+		}
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		drawGeoLine(m, l, path, width, c)
+	}
+}
+
+// width returns the width of lines.
+func (g *GraticuleOption) width() float64 {
+	if g.Width <= 0 {
+		return 1
+	}
+	return g.Width
+}
+
+// drawGeoLine draws the path projected with the layout, skipping segments
+// that jump across more than half of the map, which happens when the map
+// is rolled to wrap around the antimeridian.
+func drawGeoLine(m *image.RGBA, l layout, path []Coord, width float64, c color.Color) {
+	var pts []image.Point
+	for _, coord := range path {
+		p := l.point(coord)
+		if len(pts) > 0 && abs(p.X-pts[len(pts)-1].X) > l.mapRect.Dx()/2 {
+			strokePolyline(m, pts, width, c)
+			pts = pts[:0]
+		}
+		pts = append(pts, p)
+	}
+	strokePolyline(m, pts, width, c)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,51 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestGraticule(t *testing.T) {
+	coords := []onmap.Coord{{42.1, 19.1}} // Bar
+	m := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+		Graticule: &onmap.GraticuleOption{Spacing: 30, Color: color.NRGBA{0xff, 0xd0, 0, 0xc0}, Width: 2},
+	})
+	if err := writePng("test-graticule.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	lineColor := color.RGBA{0xff, 0, 0, 0xff}
+	worldMap := solidMap(720, 720, color.White)
+	m = onmap.Render(worldMap, nil, nil, &onmap.Options{
+		Graticule: &onmap.GraticuleOption{Spacing: 30, Color: lineColor, Width: 3},
+	})
+	rgba := m.(*image.RGBA)
+	for _, c := range []onmap.Coord{{0, 0}, {30, 45}, {60, -120}, {-60, 100}} {
+		// Parallels are unevenly spaced in Mercator.
+		p := onmap.Mercator.Convert(onmap.Coord{Lat: c.Lat, Long: c.Long + 10}, 720, 720)
+		if countColor(rgba.SubImage(image.Rect(p.X-1, p.Y-1, p.X+2, p.Y+2)), lineColor) == 0 {
+			t.Errorf("expected parallel at %v", c.Lat)
+		}
+	}
+	// No lines between them.
+	p := onmap.Mercator.Convert(onmap.Coord{Lat: 15, Long: 15}, 720, 720)
+	if countColor(rgba.SubImage(image.Rect(p.X-5, p.Y-5, p.X+5, p.Y+5)), lineColor) != 0 {
+		t.Errorf("expected no lines at %v", p)
+	}
+}
+
+func TestGraticuleScale(t *testing.T) {
+	lineColor := color.RGBA{0xff, 0, 0, 0xff}
+	worldMap := solidMap(720, 720, color.White)
+	opt := &onmap.Options{Graticule: &onmap.GraticuleOption{Spacing: 30, Color: lineColor, Width: 2}}
+	n1 := countNotColor(onmap.Render(worldMap, nil, nil, opt), color.White)
+	opt.Scale = 2
+	n2 := countNotColor(onmap.Render(worldMap, nil, nil, opt), color.White)
+	// Lines are twice as long and twice as wide.
+	if n2 < n1*3 || n2 > n1*5 {
+		t.Errorf("expected four times more line pixels, got %d and %d", n1, n2)
+	}
+}
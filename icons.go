@@ -0,0 +1,75 @@
+package onmap
+
+import (
+	"fmt"
+	"image"
+)
+
+// iconMinSide is the minimum side of the square
+// cropped from the embedded map for icons.
+const iconMinSide = 256
+
+// RenderIconSet renders the coordinates as pins on the embedded map
+// and returns square images of each of the given sizes, such as 16, 32,
+// 48, 64, and 128, keyed by size. Each image is a square crop of the map
+// centered on the pins, downscaled with area averaging.
+func RenderIconSet(coords []Coord, sizes []int) (map[int]image.Image, error) {
+	if len(coords) == 0 {
+		return nil, ErrEmptyCoords
+	}
+	for _, s := range sizes {
+		if s <= 0 {
+			return nil, fmt.Errorf("onmap: invalid icon size %d", s)
+		}
+	}
+	for i, c := range coords {
+		if !c.Valid() {
+			return nil, fmt.Errorf("%w: %v at index %d", ErrInvalidCoord, c, i)
+		}
+	}
+	m, _ := render(DefaultMap(), DefaultPin(), coords, nil)
+	mb := m.Bounds()
+	cs := project(Mercator, coords, mb.Dx(), mb.Dy())
+
+	// Square around the pins, including the pin images.
+	var r image.Rectangle
+	for _, c := range cs {
+		r = r.Union(pinRect(c, DefaultPin()))
+	}
+	side := r.Dx()
+	if r.Dy() > side {
+		side = r.Dy()
+	}
+	side += side / 5
+	if side < iconMinSide {
+		side = iconMinSide
+	}
+	if side > mb.Dx() {
+		side = mb.Dx()
+	}
+	if side > mb.Dy() {
+		side = mb.Dy()
+	}
+	center := r.Min.Add(r.Max).Div(2)
+	sq := image.Rect(0, 0, side, side).Add(center.Sub(image.Point{side / 2, side / 2}))
+	// Shift the square inside the map.
+	if sq.Min.X < 0 {
+		sq = sq.Add(image.Point{-sq.Min.X, 0})
+	}
+	if sq.Min.Y < 0 {
+		sq = sq.Add(image.Point{0, -sq.Min.Y})
+	}
+	if sq.Max.X > mb.Max.X {
+		sq = sq.Add(image.Point{mb.Max.X - sq.Max.X, 0})
+	}
+	if sq.Max.Y > mb.Max.Y {
+		sq = sq.Add(image.Point{0, mb.Max.Y - sq.Max.Y})
+	}
+
+	crop := m.SubImage(sq)
+	icons := make(map[int]image.Image, len(sizes))
+	for _, s := range sizes {
+		icons[s] = scaleImage(crop, s, s)
+	}
+	return icons, nil
+}
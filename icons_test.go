@@ -0,0 +1,36 @@
+package onmap_test
+
+import (
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderIconSet(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},           // Bar
+		{55.755833, 37.617222}, // Moscow
+	}
+	sizes := []int{16, 32, 48, 64, 128}
+	icons, err := onmap.RenderIconSet(coords, sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(icons) != len(sizes) {
+		t.Errorf("expected %d icons, got %d", len(sizes), len(icons))
+	}
+	for _, s := range sizes {
+		m, ok := icons[s]
+		if !ok {
+			t.Errorf("missing icon of size %d", s)
+			continue
+		}
+		if b := m.Bounds(); b.Dx() != s || b.Dy() != s {
+			t.Errorf("expected %dx%d icon, got %v", s, s, b)
+		}
+	}
+
+	if _, err := onmap.RenderIconSet(nil, sizes); err == nil {
+		t.Errorf("expected error for no coordinates")
+	}
+}
@@ -0,0 +1,196 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+)
+
+// LabelOption defines options for MapPinsLabeled.
+type LabelOption struct {
+	// Scale is the scale of the built-in font. If zero, 1 is used.
+	Scale int
+
+	// Font, if not nil, is used instead of the built-in font, which
+	// only has ASCII characters, for example, to draw labels in other
+	// scripts. Runes missing in the font are skipped.
+	Font Font
+
+	// Color is the color of labels. If nil, black is used.
+	Color color.Color
+
+	// Gap is the distance in pixels between the pin and its label.
+	Gap int
+
+	// AvoidOverlap, if true, moves labels that would overlap previously
+	// placed labels to the nearest free place around their pins, keeping
+	// them inside the image, and connects moved labels to their pins
+	// with leader lines.
+	AvoidOverlap bool
+
+	// LeaderColor is the color of leader lines.
+	// If nil, the color of labels is used.
+	LeaderColor color.Color
+}
+
+// MapPinsLabeled is like Render, but draws a text label next to each pin.
+// Labels are placed to the right of pins, vertically centered on the pin
+// image, or to the left of pins if they wouldn't fit into the image.
+//
+// With lopt.AvoidOverlap, overlapping labels are moved apart
+// and connected to their pins with leader lines.
+//
+// The i-th label corresponds to the i-th coordinate.
+// If lopt is nil, default options are used.
+func MapPinsLabeled(worldMap image.Image, pinParts []image.Image, coords []Coord, labels []string, lopt *LabelOption, opt *Options) image.Image {
+	if lopt == nil {
+		lopt = &LabelOption{}
+	}
+	scale := lopt.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	textColor := colorOr(lopt.Color, color.Black)
+
+	// Place labels beside the topmost pin part, usually the pin body,
+	// as drawn with scaling and anchors.
+	bodies := make([]image.Rectangle, len(coords))
+	drawn := make([]bool, len(coords))
+	sc := &scene{
+		coords: coords,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts}
+		},
+		placed: func(i int, mk marker) {
+			bodies[i], drawn[i] = image.Rectangle{mk.pt, mk.pt}, true
+			if n := len(mk.parts); n > 0 {
+				bodies[i] = mk.partRect(n - 1)
+			}
+		},
+		above: func(m *image.RGBA, _ []image.Point, l layout) {
+			var pins []image.Rectangle
+			var texts []string
+			var sizes []image.Point
+			for i, s := range labels {
+				if i >= len(coords) || !drawn[i] || s == "" {
+					continue
+				}
+				pins = append(pins, bodies[i])
+				texts = append(texts, s)
+				sizes = append(sizes, measureText(lopt.Font, s, scale))
+			}
+			rects, moved := placeLabels(pins, sizes, lopt.Gap, m.Bounds(), lopt.AvoidOverlap)
+			leaderColor := colorOr(lopt.LeaderColor, textColor)
+			for i, lr := range rects {
+				if moved[i] {
+					// Connect the nearest points of the pin and the label.
+					pr := pins[i]
+					x0, y0 := pixelCenter(nearestPoint(pr, lr.Min.Add(lr.Max).Div(2)))
+					x1, y1 := pixelCenter(nearestPoint(lr, pr.Min.Add(pr.Max).Div(2)))
+					strokeLine(m, x0, y0, x1, y1, l.px(1), leaderColor)
+				}
+			}
+			for i, lr := range rects {
+				drawTextWith(m, lopt.Font, lr.Min, texts[i], scale, textColor)
+			}
+		},
+	}
+	m, r, _ := renderScene(context.Background(), worldMap, opt, sc)
+	return finish(m, r, opt)
+}
+
+// placeLabels returns rectangles of labels of the given sizes placed
+// beside the pin rectangles: to the right of pins, vertically centered,
+// or to the left if they wouldn't fit into r. If avoid is true, labels
+// overlapping previously placed ones are moved to the nearest free
+// place around their pins inside r, which is reported in moved.
+func placeLabels(pins []image.Rectangle, sizes []image.Point, gap int, r image.Rectangle, avoid bool) (rects []image.Rectangle, moved []bool) {
+	rects = make([]image.Rectangle, len(pins))
+	moved = make([]bool, len(pins))
+	for i, pr := range pins {
+		size := sizes[i]
+		p := image.Point{
+			X: pr.Max.X + gap,
+			Y: (pr.Min.Y+pr.Max.Y)/2 - size.Y/2,
+		}
+		if p.X+size.X > r.Max.X {
+			p.X = pr.Min.X - gap - size.X
+		}
+		rects[i] = image.Rectangle{p, p.Add(size)}
+		if !avoid || !overlapsAny(rects[i], rects[:i]) {
+			continue
+		}
+		// Search for a free place on rings of increasing distance.
+		step := size.Y + 2
+		found := false
+		for d := 1; d <= 20 && !found; d++ {
+			for _, off := range ringOffsets(d) {
+				q := p.Add(image.Point{off.X * (size.X/2 + gap), off.Y * step})
+				c := clampRect(image.Rectangle{q, q.Add(size)}, r)
+				if !overlapsAny(c, rects[:i]) {
+					rects[i], moved[i], found = c, true, true
+					break
+				}
+			}
+		}
+	}
+	return rects, moved
+}
+
+// ringOffsets returns offsets on the square ring at distance d,
+// vertical ones first.
+func ringOffsets(d int) []image.Point {
+	offs := []image.Point{{0, -d}, {0, d}}
+	for y := -d; y <= d; y++ {
+		offs = append(offs, image.Point{-d, y}, image.Point{d, y})
+	}
+	for x := -d + 1; x < d; x++ {
+		if x != 0 {
+			offs = append(offs, image.Point{x, -d}, image.Point{x, d})
+		}
+	}
+	return offs
+}
+
+// overlapsAny reports whether the rectangle overlaps any of rects.
+func overlapsAny(r image.Rectangle, rects []image.Rectangle) bool {
+	for _, o := range rects {
+		if r.Overlaps(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// clampRect returns the rectangle moved inside of bounds, if it fits.
+func clampRect(r, bounds image.Rectangle) image.Rectangle {
+	var d image.Point
+	if r.Max.X > bounds.Max.X {
+		d.X = bounds.Max.X - r.Max.X
+	}
+	if r.Min.X+d.X < bounds.Min.X {
+		d.X = bounds.Min.X - r.Min.X
+	}
+	if r.Max.Y > bounds.Max.Y {
+		d.Y = bounds.Max.Y - r.Max.Y
+	}
+	if r.Min.Y+d.Y < bounds.Min.Y {
+		d.Y = bounds.Min.Y - r.Min.Y
+	}
+	return r.Add(d)
+}
+
+// nearestPoint returns the point of the rectangle nearest to p.
+func nearestPoint(r image.Rectangle, p image.Point) image.Point {
+	if p.X < r.Min.X {
+		p.X = r.Min.X
+	} else if p.X >= r.Max.X {
+		p.X = r.Max.X - 1
+	}
+	if p.Y < r.Min.Y {
+		p.Y = r.Min.Y
+	} else if p.Y >= r.Max.Y {
+		p.Y = r.Max.Y - 1
+	}
+	return p
+}
@@ -0,0 +1,153 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapPinsLabeled(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{41.9097306, 12.2558141}, // Rome
+		{55.755833, 37.617222},   // Moscow
+	}
+	labels := []string{"Bar", "Rome", "Moscow"}
+	m := onmap.MapPinsLabeled(onmap.DefaultMap(), onmap.DefaultPin(), coords, labels,
+		&onmap.LabelOption{Scale: 2, Gap: 2}, &onmap.Options{Crop: onmap.StandardCrop})
+	if err := writePng("test-labels.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	// Label near the right edge is placed to the left of the pin.
+	worldMap := solidMap(360, 360, color.White)
+	pin := solidMap(10, 10, color.RGBA{0xff, 0, 0, 0xff})
+	edge := []onmap.Coord{{0, -90}, {0, 175}}
+	m = onmap.MapPinsLabeled(worldMap, []image.Image{pin}, edge, []string{"West", "East"}, nil, nil)
+	black := color.RGBA{0, 0, 0, 0xff}
+	west := onmap.Mercator.Convert(edge[0], 360, 360)
+	if countColor(m.(*image.RGBA).SubImage(image.Rect(west.X+5, west.Y-10, west.X+40, west.Y)), black) == 0 {
+		t.Errorf("expected label to the right of the pin")
+	}
+	east := onmap.Mercator.Convert(edge[1], 360, 360)
+	if countColor(m.(*image.RGBA).SubImage(image.Rect(east.X-40, east.Y-10, east.X-5, east.Y)), black) == 0 {
+		t.Errorf("expected label to the left of the pin near the right edge")
+	}
+}
+
+// cyrillicFont is a test font with a 5x10 block glyph for each
+// Cyrillic letter followed by a pixel of spacing.
+type cyrillicFont struct{}
+
+func (cyrillicFont) Glyph(r rune) (image.Image, bool) {
+	if r < 'А' || r > 'я' {
+		return nil, false
+	}
+	m := image.NewAlpha(image.Rect(0, 0, 6, 10))
+	draw.Draw(m, image.Rect(0, 0, 5, 10), image.Opaque, image.Point{}, draw.Src)
+	return m, true
+}
+
+func TestMapPinsLabeledFont(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	pin := solidMap(10, 10, color.RGBA{0xff, 0, 0, 0xff})
+	coords := []onmap.Coord{{0, 0}}
+	lopt := &onmap.LabelOption{Font: cyrillicFont{}, Gap: 2}
+	m := onmap.MapPinsLabeled(worldMap, []image.Image{pin}, coords, []string{"Жук"}, lopt, nil)
+
+	// Glyphs are drawn to the right of the pin, vertically centered on it.
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+	label := image.Rect(p.X+5+2, p.Y-10, p.X+5+2+17, p.Y)
+	black := color.RGBA{0, 0, 0, 0xff}
+	if n := countColor(m.(*image.RGBA).SubImage(label), black); n != 3*5*10 {
+		t.Errorf("expected %d pixels of glyphs at %v, got %d", 3*5*10, label, n)
+	}
+	if n := countColor(m, black); n != 3*5*10 {
+		t.Errorf("expected only glyphs to be drawn, got %d pixels", n)
+	}
+}
+
+func TestMapPinsLabeledAvoidOverlap(t *testing.T) {
+	// Three pins within a few pixels of each other.
+	coords := []onmap.Coord{
+		{48.8566, 2.3522}, // Paris
+		{48.8, 2.45},
+		{48.9, 2.3},
+	}
+	labels := []string{"Paris", "Vincennes", "Saint-Denis"}
+	lopt := &onmap.LabelOption{Scale: 2, Gap: 2, AvoidOverlap: true, LeaderColor: color.RGBA{0x80, 0, 0, 0xff}}
+	m := onmap.MapPinsLabeled(onmap.DefaultMap(), onmap.DefaultPin(), coords, labels, lopt,
+		&onmap.Options{Crop: onmap.StandardCrop})
+	if err := writePng("test-labels-overlap.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	pins := []image.Rectangle{
+		image.Rect(100, 100, 110, 110),
+		image.Rect(102, 101, 112, 111),
+		image.Rect(98, 103, 108, 113),
+	}
+	sizes := []image.Point{{50, 14}, {80, 14}, {60, 14}}
+	bounds := image.Rect(0, 0, 200, 200)
+	rects, moved := onmap.PlaceLabels(pins, sizes, 2, bounds, true)
+	for i, r := range rects {
+		if r.Size() != sizes[i] {
+			t.Errorf("label %d: expected size %v, got %v", i, sizes[i], r.Size())
+		}
+		if !r.In(bounds) {
+			t.Errorf("label %d: %v is outside of %v", i, r, bounds)
+		}
+		for j := 0; j < i; j++ {
+			if r.Overlaps(rects[j]) {
+				t.Errorf("label %d %v overlaps label %d %v", i, r, j, rects[j])
+			}
+		}
+	}
+	if moved[0] || !moved[1] || !moved[2] {
+		t.Errorf("expected only overlapping labels to be moved, got %v", moved)
+	}
+
+	// Labels pushed off the image are pulled back inside.
+	edge := image.Rect(0, 0, 130, 40)
+	rects, _ = onmap.PlaceLabels(pins[:2], sizes[:2], 2, edge.Add(image.Pt(0, 90)), true)
+	for i, r := range rects {
+		if !r.In(edge.Add(image.Pt(0, 90))) {
+			t.Errorf("label %d: %v is outside of the image", i, r)
+		}
+	}
+	if rects[0].Overlaps(rects[1]) {
+		t.Errorf("labels overlap: %v and %v", rects[0], rects[1])
+	}
+}
+
+func TestMapPinsLabeledScale(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	pin := solidMap(10, 20, color.RGBA{0xff, 0, 0, 0xff})
+	coords := []onmap.Coord{{0, 0}}
+	black := color.RGBA{0, 0, 0, 0xff}
+
+	// The pin is scaled to 20x40 at (360, 360), so the label
+	// is to the right of x=370, centered at y=340.
+	m := onmap.MapPinsLabeled(worldMap, []image.Image{pin}, coords, []string{"Pin"},
+		&onmap.LabelOption{Gap: 2}, &onmap.Options{Scale: 2}).(*image.RGBA)
+	if n := countColor(m.SubImage(image.Rect(0, 0, 370, 720)), black); n != 0 {
+		t.Errorf("expected no label pixels over or left of the scaled pin, got %d", n)
+	}
+	if countColor(m.SubImage(image.Rect(370, 330, 420, 350)), black) == 0 {
+		t.Errorf("expected label to the right of the scaled pin")
+	}
+
+	// The pin is centered at (180, 180) with anchors from options.
+	opt := &onmap.Options{Anchors: onmap.AnchorsAt([]image.Image{pin}, 0.5, 0.5)}
+	m = onmap.MapPinsLabeled(worldMap, []image.Image{pin}, coords, []string{"Pin"},
+		&onmap.LabelOption{Gap: 2}, opt).(*image.RGBA)
+	if n := countColor(m.SubImage(image.Rect(0, 0, 360, 174)), black); n != 0 {
+		t.Errorf("expected no label pixels above the anchored pin center, got %d", n)
+	}
+	if countColor(m.SubImage(image.Rect(185, 174, 230, 186)), black) == 0 {
+		t.Errorf("expected label beside the anchored pin")
+	}
+}
@@ -0,0 +1,105 @@
+package onmap
+
+import (
+	"context"
+	"image"
+)
+
+// Names of layers returned by RenderLayers in the order of compositing.
+const (
+	LayerMap     = "map"
+	LayerLines   = "lines"
+	LayerShadows = "shadows"
+	LayerPins    = "pins"
+	LayerLabels  = "labels"
+)
+
+// LayerNames lists names of layers returned by RenderLayers
+// in the order they should be composited.
+var LayerNames = []string{LayerMap, LayerLines, LayerShadows, LayerPins, LayerLabels}
+
+// RenderLayers is like Render, but returns the rendering decomposed
+// into separate transparent layers of the final size, keyed by the names
+// listed in LayerNames: the world map flattened over opt.Background,
+// lines (such as the graticule), the first pin parts (shadows), the rest
+// of pin parts, and labels (such as cluster badges, the legend, and
+// the border). Compositing the layers in the order of LayerNames
+// reproduces the image returned by Render. Layers are resampled
+// separately for opt.Target and opt.Rotation, so that with them the
+// composited image only approximates the rendered one at edges of pins
+// and lines.
+func RenderLayers(worldMap image.Image, pinParts []image.Image, coords []Coord, opt *Options) map[string]image.Image {
+	var o Options
+	if opt != nil {
+		o = *opt
+	}
+	// The background and the border are drawn only on the bottom
+	// and the top layers.
+	mapOpt := o
+	mapOpt.Border = nil
+	lineOpt := mapOpt
+	lineOpt.Background = nil
+	labelOpt := o
+	labelOpt.Background = nil
+
+	layer := func(layers sceneLayer, opt *Options) image.Image {
+		m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+			coords: coords,
+			mk: func(i int, pt image.Point) marker {
+				return marker{pt: pt, parts: pinParts}
+			},
+			layers: layers,
+		})
+		return finish(m, r, opt)
+	}
+	return map[string]image.Image{
+		LayerMap:     layer(sceneMap, &mapOpt),
+		LayerLines:   layer(sceneLines, &lineOpt),
+		LayerShadows: layer(sceneShadows, &lineOpt),
+		LayerPins:    layer(scenePins, &lineOpt),
+		LayerLabels:  layer(sceneLabels, &labelOpt),
+	}
+}
+
+// Layer is a set of coordinates drawn with the same pin parts.
+type Layer struct {
+	Coords   []Coord
+	PinParts []image.Image
+}
+
+// emptyPart is a pin part that draws nothing.
+var emptyPart image.Image = image.NewRGBA(image.Rectangle{})
+
+// MapLayers returns an image with pins of all layers drawn on the world
+// map in a single pass, cropped around coordinates of all layers.
+//
+// Pin parts of layers are aligned by their last part, which is drawn
+// for all pins ordered by latitude, so lower pins overlap upper pins
+// regardless of their layers. Shadows and other preceding parts
+// are drawn below them.
+//
+// If crop is nil, doesn't crop the image.
+func MapLayers(proj Projection, worldMap image.Image, layers []Layer, crop *CropOption) image.Image {
+	n := 0
+	for _, l := range layers {
+		if len(l.PinParts) > n {
+			n = len(l.PinParts)
+		}
+	}
+	var pins []Pin
+	for _, l := range layers {
+		parts := l.PinParts
+		if len(parts) < n {
+			parts = make([]image.Image, n)
+			pad := n - len(l.PinParts)
+			for i := 0; i < pad; i++ {
+				parts[i] = emptyPart
+			}
+			copy(parts[pad:], l.PinParts)
+		}
+		for _, c := range l.Coords {
+			pins = append(pins, Pin{Coord: c, Parts: parts})
+		}
+	}
+	return RenderStyled(worldMap, pins, nil, &Options{Projection: proj, Crop: crop})
+}
@@ -0,0 +1,125 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderLayers(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{42.441286, 19.262892},   // Podgorica
+		{41.9097306, 12.2558141}, // Rome
+	}
+	legend := &onmap.LegendOverlay{Entries: []onmap.LegendEntry{{Color: color.Black, Label: "Cities"}}}
+	tests := []struct {
+		name string
+		opt  *onmap.Options
+		// mismatches is the number of pixels allowed to differ.
+		mismatches int
+	}{
+		{"cluster", &onmap.Options{
+			Crop:    onmap.StandardCrop,
+			Cluster: &onmap.ClusterOption{Radius: 30},
+		}, 0},
+		{"overlays", &onmap.Options{
+			Crop:       onmap.StandardCrop,
+			Background: color.White,
+			Graticule:  &onmap.GraticuleOption{Spacing: 5, Width: 2},
+			Legend:     legend,
+			Border:     &onmap.BorderOption{Color: color.RGBA{0xc0, 0, 0, 0xff}, Width: 3},
+		}, 0},
+		{"rotation", &onmap.Options{
+			Crop:     onmap.StandardCrop,
+			Rotation: &onmap.RotationOption{Bearing: 30, Center: coords[0]},
+		}, 0},
+		// Layers are rotated separately.
+		{"rotated lines", &onmap.Options{
+			Crop:      onmap.StandardCrop,
+			Graticule: &onmap.GraticuleOption{Spacing: 5},
+			Rotation:  &onmap.RotationOption{Bearing: 30, Center: coords[0]},
+		}, 640 * 543 / 50},
+		// Layers are resized separately.
+		{"target", &onmap.Options{
+			Crop:   onmap.StandardCrop,
+			Target: &onmap.TargetOption{Width: 320, Height: 200},
+		}, 320 * 200 / 50},
+	}
+	for _, tt := range tests {
+		expected := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, tt.opt)
+		layers := onmap.RenderLayers(onmap.DefaultMap(), onmap.DefaultPin(), coords, tt.opt)
+		if len(layers) != len(onmap.LayerNames) {
+			t.Fatalf("%s: expected %d layers, got %d", tt.name, len(onmap.LayerNames), len(layers))
+		}
+
+		b := expected.Bounds()
+		m := image.NewRGBA(b)
+		for _, name := range onmap.LayerNames {
+			l := layers[name]
+			if l.Bounds() != b {
+				t.Fatalf("%s: layer %s: expected bounds %v, got %v", tt.name, name, b, l.Bounds())
+			}
+			draw.Draw(m, b, l, b.Min, draw.Over)
+		}
+		mismatches := 0
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				r1, g1, b1, a1 := expected.At(x, y).RGBA()
+				r2, g2, b2, a2 := m.At(x, y).RGBA()
+				d := abs(int(r1)-int(r2)) + abs(int(g1)-int(g2)) + abs(int(b1)-int(b2)) + abs(int(a1)-int(a2))
+				if d > 4*0x200 {
+					mismatches++
+				}
+			}
+		}
+		if mismatches > tt.mismatches {
+			t.Errorf("%s: composited layers differ from render in %d pixels", tt.name, mismatches)
+		}
+	}
+}
+
+func TestMapLayers(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	dot := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(dot, dot.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+	pin, shadow := onmap.GeneratePin(48, color.RGBA{0x20, 0x40, 0xe0, 0xff}, color.Black)
+
+	customer := onmap.Coord{50, 10}
+	store := onmap.Coord{49.8, 10} // slightly lower
+	layers := []onmap.Layer{
+		{Coords: []onmap.Coord{customer}, PinParts: []image.Image{dot}},
+		{Coords: []onmap.Coord{store}, PinParts: []image.Image{shadow, pin}},
+	}
+	worldMap := onmap.DefaultMap()
+	b := worldMap.Bounds()
+	m := onmap.MapLayers(onmap.Mercator, worldMap, layers, nil)
+	if m.Bounds() != b {
+		t.Fatalf("expected bounds %v, got %v", b, m.Bounds())
+	}
+	p := onmap.Mercator.Convert(customer, b.Dx(), b.Dy())
+	if s := onmap.Mercator.Convert(store, b.Dx(), b.Dy()); s.Y <= p.Y {
+		t.Fatalf("expected store below customer: %v, %v", s, p)
+	}
+	// The center of the dot must be covered by the lower store pin.
+	if c := color.RGBAModel.Convert(m.At(p.X, p.Y-5)); c == red {
+		t.Errorf("expected the lower pin to overlap the upper dot")
+	}
+
+	// Swapped: the lower dot overlaps the upper pin.
+	layers[0].Coords, layers[1].Coords = []onmap.Coord{store}, []onmap.Coord{customer}
+	m = onmap.MapLayers(onmap.Mercator, worldMap, layers, nil)
+	p = onmap.Mercator.Convert(store, b.Dx(), b.Dy())
+	if c := color.RGBAModel.Convert(m.At(p.X, p.Y-5)); c != red {
+		t.Errorf("expected the lower dot to overlap the upper pin, got %v", c)
+	}
+
+	// Crop is computed across all layers.
+	cropped := onmap.MapLayers(onmap.Mercator, worldMap, layers, onmap.StandardCrop)
+	if cropped.Bounds().Dx() >= b.Dx() {
+		t.Errorf("expected cropped image, got %v", cropped.Bounds())
+	}
+}
@@ -0,0 +1,174 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// LegendEntry is an entry of a legend.
+type LegendEntry struct {
+	// Color is the color of the swatch drawn if Symbol has no pin image.
+	// If nil, gray is used.
+	Color color.Color
+
+	// Label is the text of the entry.
+	Label string
+
+	// Symbol is the style of the pin drawn as the swatch.
+	// If Symbol.Pin is nil, a square of Color is drawn instead.
+	Symbol PinStyle
+
+	// Image, if not nil, is drawn as the swatch scaled to fit,
+	// instead of Symbol or Color, for example, a marker image.
+	Image image.Image
+}
+
+// LegendOption defines options for RenderLegend.
+type LegendOption struct {
+	// Scale is the scale of the built-in font and swatches.
+	// If zero, 1 is used.
+	Scale int
+
+	// TextColor is the color of labels. If nil, black is used.
+	TextColor color.Color
+
+	// Background is the color of the legend background.
+	// If nil, it's transparent.
+	Background color.Color
+}
+
+// RenderLegend returns an image with the legend listing entries, each
+// with a swatch and a label, sized to fit them. If opt is nil, uses
+// the default options.
+func RenderLegend(entries []LegendEntry, opt *LegendOption) image.Image {
+	if opt == nil {
+		opt = &LegendOption{}
+	}
+	scale := opt.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	pad := 4 * scale
+	row := 12 * scale
+
+	labelWidth := 0
+	for _, e := range entries {
+		if w := textSize(e.Label, scale).X; w > labelWidth {
+			labelWidth = w
+		}
+	}
+	width := pad + row + pad + labelWidth + pad
+	height := pad
+	if len(entries) > 0 {
+		height += len(entries)*(row+pad) - pad
+	}
+	height += pad
+
+	m := image.NewRGBA(image.Rect(0, 0, width, height))
+	if opt.Background != nil {
+		draw.Draw(m, m.Bounds(), image.NewUniform(opt.Background), image.Point{}, draw.Src)
+	}
+	textColor := colorOr(opt.TextColor, color.Black)
+	for i, e := range entries {
+		y := pad + i*(row+pad)
+		swatch := image.Rect(pad, y, pad+row, y+row)
+		if e.Image != nil {
+			drawSwatch(m, swatch, e.Image)
+		} else if e.Symbol.Pin != nil {
+			drawSwatchPin(m, swatch, e.Symbol)
+		} else {
+			inset := swatch.Inset(scale)
+			draw.Draw(m, inset, image.NewUniform(colorOr(e.Color, color.Gray{0x80})), image.Point{}, draw.Over)
+		}
+		ty := y + (row-glyphHeight*scale)/2
+		drawText(m, image.Point{swatch.Max.X + pad, ty}, e.Label, scale, textColor)
+	}
+	return m
+}
+
+// drawSwatchPin draws the pin of the style scaled to fit into the rectangle.
+func drawSwatchPin(m *image.RGBA, r image.Rectangle, style PinStyle) {
+	for _, p := range PinParts(style) {
+		drawSwatch(m, r, p)
+	}
+}
+
+// drawSwatch draws the image scaled to fit into the rectangle
+// at the bottom center of it.
+func drawSwatch(m *image.RGBA, r image.Rectangle, p image.Image) {
+	b := p.Bounds()
+	s := math.Min(float64(r.Dx())/float64(b.Dx()), float64(r.Dy())/float64(b.Dy()))
+	sp := scaleBy(p, s)
+	sb := sp.Bounds()
+	at := r.Min.Add(image.Point{(r.Dx() - sb.Dx()) / 2, r.Dy() - sb.Dy()})
+	draw.Draw(m, sb.Add(at), sp, image.Point{}, draw.Over)
+}
+
+// Corner is a corner of the image.
+type Corner int
+
+// Corners of the image.
+const (
+	TopLeft Corner = iota
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// LegendOverlay defines a legend drawn over the rendered image.
+type LegendOverlay struct {
+	// Entries are entries of the legend.
+	Entries []LegendEntry
+
+	// Corner is the corner of the image to draw the legend in.
+	Corner Corner
+
+	// Margin is the distance in pixels between the legend and the edges
+	// of the image. If zero, 8 is used.
+	Margin int
+
+	// Style defines the style of the legend. If nil or its Background
+	// is nil, the legend is drawn over semi-transparent white.
+	Style *LegendOption
+}
+
+// draw draws the legend in the corner of the rectangle r of the image.
+// The legend is clipped if it doesn't fit into r.
+func (lo *LegendOverlay) draw(m draw.Image, r image.Rectangle) {
+	var style LegendOption
+	if lo.Style != nil {
+		style = *lo.Style
+	}
+	if style.Background == nil {
+		style.Background = color.NRGBA{0xff, 0xff, 0xff, 0xc0}
+	}
+	legend := RenderLegend(lo.Entries, &style)
+	size := legend.Bounds().Size()
+	margin := lo.margin()
+	at := r.Min.Add(image.Point{margin, margin})
+	if lo.Corner == TopRight || lo.Corner == BottomRight {
+		at.X = r.Max.X - margin - size.X
+	}
+	if lo.Corner == BottomLeft || lo.Corner == BottomRight {
+		at.Y = r.Max.Y - margin - size.Y
+	}
+	// Keep the top-left corner of the legend inside r.
+	if at.X < r.Min.X {
+		at.X = r.Min.X
+	}
+	if at.Y < r.Min.Y {
+		at.Y = r.Min.Y
+	}
+	dr := image.Rectangle{at, at.Add(size)}.Intersect(r)
+	draw.Draw(m, dr, legend, legend.Bounds().Min.Add(dr.Min.Sub(at)), draw.Over)
+}
+
+// margin returns the distance between the legend and the edges of the image.
+func (lo *LegendOverlay) margin() int {
+	if lo.Margin == 0 {
+		return 8
+	}
+	return lo.Margin
+}
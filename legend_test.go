@@ -0,0 +1,88 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderLegend(t *testing.T) {
+	entries := []onmap.LegendEntry{
+		{Color: color.RGBA{0xff, 0, 0, 0xff}, Label: "Stores"},
+		{Label: "Warehouses", Symbol: onmap.PinStyle{Pin: onmap.DefaultPin()[1]}},
+	}
+	opt := &onmap.LegendOption{Background: color.White}
+	m := onmap.RenderLegend(entries, opt)
+	short := onmap.RenderLegend(entries[:1], opt)
+	if m.Bounds().Dx() <= short.Bounds().Dx() {
+		t.Errorf("expected legend to be wider for longer labels: %v vs %v", m.Bounds(), short.Bounds())
+	}
+	if m.Bounds().Dy() <= short.Bounds().Dy() {
+		t.Errorf("expected legend to be taller for more entries: %v vs %v", m.Bounds(), short.Bounds())
+	}
+
+	// Each row has a swatch and a label.
+	rgba := m.(*image.RGBA)
+	rows := m.Bounds().Dy() / len(entries)
+	for i := range entries {
+		swatch := rgba.SubImage(image.Rect(0, i*rows, 18, (i+1)*rows))
+		if countNotColor(swatch, color.White) == 0 {
+			t.Errorf("entry %d: expected swatch", i)
+		}
+		label := rgba.SubImage(image.Rect(20, i*rows, m.Bounds().Dx(), (i+1)*rows))
+		if countColor(label, color.Black) == 0 {
+			t.Errorf("entry %d: expected label", i)
+		}
+	}
+}
+
+func TestRenderLegendOverlay(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	dot := solidMap(8, 8, red)
+	entries := []onmap.LegendEntry{
+		{Label: "Headquarters", Image: dot},
+		{Label: "Branches", Symbol: onmap.PinStyle{Pin: onmap.DefaultPin()[1]}},
+	}
+	coords := []onmap.Coord{{51.5, -0.1}, {48.9, 2.4}}
+	m := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+		Crop:   onmap.StandardCrop,
+		Legend: &onmap.LegendOverlay{Entries: entries, Corner: onmap.BottomRight},
+	})
+	if err := writePng("test-legend.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	worldMap := solidMap(360, 360, color.Black)
+	legend := onmap.RenderLegend(entries, nil).Bounds().Size()
+	for _, corner := range []onmap.Corner{onmap.TopLeft, onmap.TopRight, onmap.BottomLeft, onmap.BottomRight} {
+		m := onmap.Render(worldMap, nil, nil, &onmap.Options{
+			Crop:   &onmap.CropOption{MinWidth: 200, MinHeight: 100},
+			Legend: &onmap.LegendOverlay{Entries: entries, Corner: corner},
+		})
+		b := m.Bounds()
+		// The legend is inside the image at the margin from its edges.
+		want := image.Rectangle{b.Min.Add(image.Pt(8, 8)), b.Min.Add(image.Pt(8, 8)).Add(legend)}
+		if corner == onmap.TopRight || corner == onmap.BottomRight {
+			want = want.Add(image.Pt(b.Dx()-16-legend.X, 0))
+		}
+		if corner == onmap.BottomLeft || corner == onmap.BottomRight {
+			want = want.Add(image.Pt(0, b.Dy()-16-legend.Y))
+		}
+		if !want.In(b) {
+			t.Fatalf("corner %d: legend %v doesn't fit into %v", corner, want, b)
+		}
+		rgba := m.(*image.RGBA)
+		// Semi-transparent white over black.
+		if c := m.At(want.Min.X, want.Min.Y); !sameColor(c, color.Gray{0xc0}) {
+			t.Errorf("corner %d: expected legend background at %v, got %v", corner, want.Min, c)
+		}
+		if countColor(rgba.SubImage(want), red) == 0 {
+			t.Errorf("corner %d: expected image swatch in the legend", corner)
+		}
+		if c := m.At(want.Min.X-1, want.Min.Y-1); !sameColor(c, color.Black) {
+			t.Errorf("corner %d: expected map outside of the legend, got %v", corner, c)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package onmap
+
+import "image"
+
+// Locator converts coordinates to pixels of a rendered image
+// for a fixed map size and crop rectangle.
+//
+// Locator is safe for concurrent use.
+type Locator struct {
+	l    layout
+	crop image.Rectangle
+}
+
+// NewLocator returns a new locator for the map of the given size in the
+// projection, cropped to the given rectangle, such as the bounds of the
+// image returned by MapPinsProjection. If the map is rolled with
+// CropOption.WrapAntimeridian, use NewCropLocator instead.
+func NewLocator(proj Projection, mapWidth, mapHeight int, crop image.Rectangle) *Locator {
+	r := image.Rect(0, 0, mapWidth, mapHeight)
+	return &Locator{
+		l:    layout{proj: proj, mapRect: r, canvas: r},
+		crop: crop,
+	}
+}
+
+// NewCropLocator returns a new locator for the image that
+// MapPinsProjection renders on the map of the given size in the
+// projection for the given coordinates and crop options, including
+// the map rolled with CropOption.WrapAntimeridian.
+func NewCropLocator(proj Projection, mapWidth, mapHeight int, coords []Coord, crop *CropOption) *Locator {
+	opt := &Options{Projection: proj, Crop: crop}
+	cs, l := layoutPoints(image.Rect(0, 0, mapWidth, mapHeight), coords, opt)
+	return &Locator{l: l, crop: opt.cropRect(l.visible(coords, cs), l)}
+}
+
+// Pixel returns the pixel of the cropped image for the coordinate
+// relative to the top-left corner of the crop rectangle.
+// It returns false if the pixel is outside of the crop rectangle.
+func (l *Locator) Pixel(c Coord) (image.Point, bool) {
+	p := l.l.point(c)
+	return p.Sub(l.crop.Min), p.In(l.crop)
+}
@@ -0,0 +1,33 @@
+package onmap_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestLocator(t *testing.T) {
+	crop := image.Rect(100, 100, 200, 200)
+	l := onmap.NewLocator(onmap.Mercator, 360, 360, crop)
+
+	p, ok := l.Pixel(onmap.Coord{0, 0})
+	if !ok {
+		t.Errorf("expected center to be inside the crop")
+	}
+	if expected := (image.Point{80, 80}); p != expected {
+		t.Errorf("expected pixel %v, got %v", expected, p)
+	}
+	// Repeated result is the same.
+	if p2, _ := l.Pixel(onmap.Coord{0, 0}); p2 != p {
+		t.Errorf("expected the same pixel %v, got %v", p, p2)
+	}
+
+	p, ok = l.Pixel(onmap.Coord{0, -170})
+	if ok {
+		t.Errorf("expected pixel %v to be outside the crop", p)
+	}
+	if expected := (image.Point{-90, 80}); p != expected {
+		t.Errorf("expected pixel %v, got %v", expected, p)
+	}
+}
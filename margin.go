@@ -0,0 +1,141 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// MarginSide is a side of the image where labels are placed.
+type MarginSide int
+
+const (
+	MarginRight MarginSide = iota
+	MarginLeft
+)
+
+// MarginLabelOption defines options for MapMarginLabels.
+type MarginLabelOption struct {
+	// Side is the side of the map where the margin is added.
+	Side MarginSide
+
+	// Width is the width of the margin in pixels. If zero, 120 is used.
+	Width int
+
+	// Scale is the scale of the built-in font. If zero, 1 is used.
+	Scale int
+
+	// TextColor is the color of labels. If nil, black is used.
+	TextColor color.Color
+
+	// LineColor is the color of leader lines. If nil, gray is used.
+	LineColor color.Color
+
+	// Background is the color of the margin. If nil, white is used.
+	Background color.Color
+}
+
+// MapMarginLabels is like Render, but adds a margin of the given width
+// to the side of the image and places labels there, stacked in latitude
+// order, with leader lines connecting each pin to its label.
+// Labels never overlap each other or the map.
+//
+// The i-th label corresponds to the i-th coordinate.
+// If ml is nil, uses the default options.
+func MapMarginLabels(worldMap image.Image, pinParts []image.Image, coords []Coord, labels []string, ml *MarginLabelOption, opt *Options) image.Image {
+	if ml == nil {
+		ml = &MarginLabelOption{}
+	}
+	scale := ml.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	width := ml.Width
+	if width == 0 {
+		width = 120
+	}
+	if opt != nil && opt.Scale != 0 {
+		scale = int(math.Max(1, math.Round(float64(scale)*opt.Scale)))
+		width = scaleInt(width, opt.Scale)
+	}
+	textColor := colorOr(ml.TextColor, color.Black)
+	lineColor := colorOr(ml.LineColor, color.Gray{0x80})
+
+	// Stack labels in latitude order, trying to place each
+	// label at the level of its pin without overlapping.
+	var order []int
+	var ys []int
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		coords: coords,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts}
+		},
+		beneath: func(m *image.RGBA, cs []image.Point, l layout) {
+			n := len(labels)
+			if n > len(cs) {
+				n = len(cs)
+			}
+			order = make([]int, n)
+			for i := range order {
+				order[i] = i
+			}
+			sort.SliceStable(order, func(i, j int) bool {
+				return cs[order[i]].Y < cs[order[j]].Y
+			})
+			lineHeight := (glyphHeight + 2) * scale
+			ys = make([]int, n)
+			next := m.Rect.Min.Y
+			for k, i := range order {
+				y := cs[i].Y - glyphHeight*scale/2
+				if y < next {
+					y = next
+				}
+				ys[k] = y
+				next = y + lineHeight
+			}
+			// Pull labels back up if they overflow the bottom.
+			bottom := m.Rect.Max.Y - glyphHeight*scale
+			for k := n - 1; k >= 0 && ys[k] > bottom; k-- {
+				ys[k] = bottom
+				bottom -= lineHeight
+			}
+			// Draw leader lines to the edge of the map beneath pins.
+			lineX := m.Rect.Max.X
+			if ml.Side == MarginLeft {
+				lineX = m.Rect.Min.X
+			}
+			for k, i := range order {
+				end := image.Point{lineX, ys[k] + glyphHeight*scale/2}
+				strokePolyline(m, []image.Point{cs[i], end}, l.px(1), lineColor)
+			}
+		},
+	})
+	if opt != nil && opt.Background != nil {
+		m = flatten(m, opt.Background)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, r.Dx()+width, r.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(colorOr(ml.Background, color.White)), image.Point{}, draw.Src)
+	mapRect := image.Rect(0, 0, r.Dx(), r.Dy())
+	marginX := r.Dx() + scale*2
+	if ml.Side == MarginLeft {
+		mapRect = mapRect.Add(image.Point{width, 0})
+		marginX = scale * 2
+	}
+	draw.Draw(out, mapRect, m, r.Min, draw.Over)
+	for k, i := range order {
+		drawText(out, image.Point{marginX, ys[k] - r.Min.Y}, labels[i], scale, textColor)
+	}
+	return opt.frame(opt.resize(out))
+}
+
+// colorOr returns c if it's not nil, otherwise def.
+func colorOr(c, def color.Color) color.Color {
+	if c == nil {
+		return def
+	}
+	return c
+}
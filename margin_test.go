@@ -0,0 +1,49 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapMarginLabels(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{
+		{0, 0},
+		{20, 10},
+	}
+	lineColor := color.RGBA{0xff, 0, 0, 0xff}
+	ml := &onmap.MarginLabelOption{Side: onmap.MarginRight, Width: 100, LineColor: lineColor}
+	m := onmap.MapMarginLabels(worldMap, nil, coords, []string{"Zero", "North"}, ml, nil)
+	if b := m.Bounds(); b.Dx() != 460 || b.Dy() != 360 {
+		t.Fatalf("expected 460x360 image, got %v", b)
+	}
+
+	// Labels are in the margin, the map outside pins is untouched.
+	margin := m.(*image.RGBA).SubImage(image.Rect(360, 0, 460, 360))
+	if countNotColor(margin, color.White) == 0 {
+		t.Errorf("expected labels in the margin")
+	}
+
+	// Leader line from the pin at the center goes
+	// horizontally to its label.
+	for _, x := range []int{200, 270, 350} {
+		if c := m.At(x, 180); !sameColor(c, lineColor) {
+			t.Errorf("expected leader line at (%d, 180), got %v", x, c)
+		}
+	}
+}
+
+func TestMapMarginLabelsDefault(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	m := onmap.MapMarginLabels(worldMap, nil, []onmap.Coord{{0, 0}}, []string{"Zero"}, nil, nil)
+	if b := m.Bounds(); b.Dx() != 480 || b.Dy() != 360 {
+		t.Fatalf("expected 480x360 image, got %v", b)
+	}
+	margin := m.(*image.RGBA).SubImage(image.Rect(360, 0, 480, 360))
+	if countNotColor(margin, color.White) == 0 {
+		t.Errorf("expected labels in the margin")
+	}
+}
@@ -0,0 +1,101 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+)
+
+// NumberOption defines options for MapNumberedPins.
+type NumberOption struct {
+	// Scale is the scale of the built-in font. If zero, 1 is used.
+	Scale int
+
+	// Font, if not nil, is used instead of the built-in font.
+	Font Font
+
+	// Color is the color of numbers. If nil, black or white
+	// is used depending on the color of the pin head.
+	Color color.Color
+}
+
+// MapNumberedPins is like Render, but draws the sequential number of each
+// pin, starting from 1 in the order of coordinates, centered on its head.
+// The head is assumed to be the topmost square part of the last pin part.
+// Numbers are drawn into pins, so they are covered by overlapping pins
+// like the rest of the pin.
+//
+// If nopt is nil, default options are used.
+func MapNumberedPins(worldMap image.Image, pinParts []image.Image, coords []Coord, nopt *NumberOption, opt *Options) image.Image {
+	if nopt == nil {
+		nopt = &NumberOption{}
+	}
+	scale := nopt.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	var head image.Point
+	if len(pinParts) > 0 {
+		head = pinHead(pinParts[len(pinParts)-1])
+	}
+	m, r := renderMarkers(worldMap, coords, opt, func(i int, pt image.Point) marker {
+		if len(pinParts) == 0 {
+			return marker{pt: pt}
+		}
+		parts := make([]image.Image, len(pinParts))
+		copy(parts, pinParts)
+		parts[len(parts)-1] = numberPin(pinParts[len(pinParts)-1], head, strconv.Itoa(i+1), nopt.Font, scale, nopt.Color)
+		return marker{pt: pt, parts: parts}
+	})
+	return finish(m, r, opt)
+}
+
+// pinHead returns the center of the pin head relative to the top-left
+// corner of the pin image, which is the center of the square as wide as
+// the opaque part of the image at its top.
+func pinHead(pin image.Image) image.Point {
+	b := pin.Bounds()
+	var r image.Rectangle
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := pin.At(x, y).RGBA(); a >= 0x8000 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if r.Empty() {
+		return image.Point{b.Dx() / 2, b.Dy() / 2}
+	}
+	return image.Point{(r.Min.X + r.Max.X) / 2, r.Min.Y + r.Dx()/2}.Sub(b.Min)
+}
+
+// numberPin returns a copy of the pin image with the text drawn with
+// the font (or the built-in font if it's nil) centered at head. If c
+// is nil, the text is black or white depending on the color of the pin
+// at head.
+func numberPin(pin image.Image, head image.Point, text string, f Font, scale int, c color.Color) image.Image {
+	b := pin.Bounds()
+	m := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(m, m.Bounds(), pin, b.Min, draw.Src)
+	bg := m.At(head.X, head.Y)
+	if c == nil {
+		c = contrastColor(bg)
+	}
+	// Clear highlights and other details under the number.
+	size := measureText(f, text, scale)
+	x, y := pixelCenter(head)
+	fillCircle(m, x, y, math.Hypot(float64(size.X), float64(size.Y))/2, bg)
+	drawTextWith(m, f, head.Sub(size.Div(2)), text, scale, c)
+	return m
+}
+
+// contrastColor returns black for light colors and white for dark ones.
+func contrastColor(c color.Color) color.Color {
+	r, g, b, _ := color.NRGBAModel.Convert(c).RGBA()
+	if 0.299*float64(r)+0.587*float64(g)+0.114*float64(b) > 0x7fff {
+		return color.Black
+	}
+	return color.White
+}
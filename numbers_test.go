@@ -0,0 +1,42 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapNumberedPins(t *testing.T) {
+	coords := []onmap.Coord{
+		{41.9097306, 12.2558141}, // Rome
+		{43.7800607, 11.170928},  // Florence
+		{45.4628329, 9.1076924},  // Milano
+		{42.1, 19.1},             // Bar
+		{42.441286, 19.262892},   // Podgorica
+	}
+	m := onmap.MapNumberedPins(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.NumberOption{Scale: 1},
+		&onmap.Options{Crop: onmap.StandardCrop})
+	if err := writePng("test-numbers.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	// Light pins get dark numbers.
+	worldMap := solidMap(360, 360, color.White)
+	yellow := color.RGBA{0xff, 0xe0, 0x20, 0xff}
+	pin := solidMap(20, 30, yellow)
+	two := []onmap.Coord{{0, -90}, {0, 90}}
+	m = onmap.MapNumberedPins(worldMap, []image.Image{pin}, two, nil, nil)
+	if countColor(m, color.RGBA{0, 0, 0, 0xff}) == 0 {
+		t.Errorf("expected black numbers on light pins")
+	}
+
+	// Numbers follow the order of coordinates.
+	west := onmap.Mercator.Convert(two[0], 360, 360)
+	r := image.Rect(west.X-10, west.Y-30, west.X+10, west.Y)
+	rev := onmap.MapNumberedPins(worldMap, []image.Image{pin}, []onmap.Coord{two[1], two[0]}, nil, nil)
+	if countColor(m.(*image.RGBA).SubImage(r), yellow) == countColor(rev.(*image.RGBA).SubImage(r), yellow) {
+		t.Errorf("expected different numbers on the pin when order changes")
+	}
+}
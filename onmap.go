@@ -1,15 +1,24 @@
 // Package onmap puts pins into a world map image.
+//
+// All functions, except SetDefaultAssets and ResetDefaultAssets, are safe
+// for concurrent use. They don't modify images, coordinates, and options
+// passed to them, so the same values can be shared between goroutines,
+// as long as callers don't modify them while they are in use. This
+// includes StandardCrop and other package-level default options, which
+// must not be modified: copy them instead. The embedded assets are
+// decoded once on the first use.
 package onmap
 
 import (
 	"bytes"
 	_ "embed"
+	"errors"
+	"fmt"
 	"image"
 	"image/draw"
 	_ "image/jpeg"
 	_ "image/png"
 	"math"
-	"sort"
 	"sync"
 )
 
@@ -24,37 +33,156 @@ var mercatorData []byte
 
 var (
 	mercatorImg     image.Image
+	mercatorErr     error
 	defaultPinParts []image.Image
+	defaultPinErr   error
 
 	mercatorOnce sync.Once
 	pinOnce      sync.Once
 )
 
-// DefaultMap returns the default map (Mercator projection).
-func DefaultMap() image.Image {
+// Load decodes the embedded world map and pin images, which otherwise
+// happens lazily on the first use, and returns an error if they can't
+// be decoded. Callers that need to handle such failure gracefully
+// should call it before using the embedded assets.
+func Load() error {
+	if _, err := loadMap(); err != nil {
+		return err
+	}
+	_, err := loadPin()
+	return err
+}
+
+var (
+	assetsMu   sync.RWMutex
+	customMap  image.Image
+	customPins []image.Image
+)
+
+// SetDefaultAssets replaces the embedded world map and pin images returned
+// by DefaultMap and DefaultPin, and used by functions such as Pins, with the
+// given images, for example, to use a smaller map in tests. The world map
+// must be in Mercator projection. It returns an error if any image is nil.
+//
+// It must not be called concurrently with rendering. Use ResetDefaultAssets
+// to return to the embedded images.
+func SetDefaultAssets(worldMap, pin, pinShadow image.Image) error {
+	if worldMap == nil || pin == nil || pinShadow == nil {
+		return errors.New("onmap: default assets must not be nil")
+	}
+	assetsMu.Lock()
+	customMap = worldMap
+	customPins = []image.Image{pinShadow, pin}
+	assetsMu.Unlock()
+	resetBases()
+	return nil
+}
+
+// ResetDefaultAssets returns to using the embedded world map and
+// pin images after SetDefaultAssets.
+func ResetDefaultAssets() {
+	assetsMu.Lock()
+	customMap = nil
+	customPins = nil
+	assetsMu.Unlock()
+	resetBases()
+}
+
+func loadMap() (image.Image, error) {
+	m, _, err := loadMapCustom()
+	return m, err
+}
+
+// loadMapCustom is like loadMap, but also reports whether the embedded
+// map was replaced with SetDefaultAssets, reading both under the same lock.
+func loadMapCustom() (image.Image, bool, error) {
+	assetsMu.RLock()
+	m := customMap
+	assetsMu.RUnlock()
+	if m != nil {
+		return m, true, nil
+	}
 	mercatorOnce.Do(func() {
-		mercatorImg = decodeImage(mercatorData)
+		mercatorImg, mercatorErr = decodeImage(mercatorData)
 	})
-	return mercatorImg
+	return mercatorImg, false, mercatorErr
 }
 
-// DefaultPin returns default pin images.
-func DefaultPin() []image.Image {
+func loadPin() ([]image.Image, error) {
+	assetsMu.RLock()
+	parts := customPins
+	assetsMu.RUnlock()
+	if parts != nil {
+		return parts, nil
+	}
 	pinOnce.Do(func() {
-		defaultPinParts = []image.Image{decodeImage(pinShadowData), decodeImage(pinData)}
+		shadow, err := decodeImage(pinShadowData)
+		if err != nil {
+			defaultPinErr = err
+			return
+		}
+		pin, err := decodeImage(pinData)
+		if err != nil {
+			defaultPinErr = err
+			return
+		}
+		defaultPinParts = []image.Image{shadow, pin}
 	})
-	return defaultPinParts
+	return defaultPinParts, defaultPinErr
 }
 
-func decodeImage(data []byte) image.Image {
-	m, _, err := image.Decode(bytes.NewReader(data))
+// DefaultMap returns the default map (Mercator projection),
+// which is the embedded map unless replaced with SetDefaultAssets.
+// It panics if the embedded map can't be decoded, see Load.
+func DefaultMap() image.Image {
+	m, err := loadMap()
 	if err != nil {
 		panic(err.Error())
 	}
 	return m
 }
 
-// StandardCrop is the standard crop.
+// DefaultPin returns default pin images.
+// It panics if the embedded pin can't be decoded, see Load.
+func DefaultPin() []image.Image {
+	parts, err := loadPin()
+	if err != nil {
+		panic(err.Error())
+	}
+	return parts
+}
+
+// PinImage returns a copy of the default pin image without the shadow.
+// It panics if the embedded pin can't be decoded, see Load.
+func PinImage() image.Image {
+	return copyImage(DefaultPin()[1])
+}
+
+// PinShadowImage returns a copy of the default pin shadow image.
+// It panics if the embedded pin can't be decoded, see Load.
+func PinShadowImage() image.Image {
+	return copyImage(DefaultPin()[0])
+}
+
+// copyImage returns a copy of the image with bounds starting at (0, 0).
+func copyImage(m image.Image) *image.RGBA {
+	b := m.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), m, b.Min, draw.Src)
+	return dst
+}
+
+func decodeImage(data []byte) (image.Image, error) {
+	m, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("onmap: decoding embedded image: %w", err)
+	}
+	return m, nil
+}
+
+// StandardCrop is the standard crop for the default map, which is
+// at least a third of the map in each dimension. For other maps,
+// use StandardCropFor. It's shared, so it must not be modified.
 var StandardCrop = &CropOption{
 	Bound:         100,
 	MinWidth:      640,
@@ -62,6 +190,18 @@ var StandardCrop = &CropOption{
 	PreserveRatio: true,
 }
 
+// StandardCropFor returns the standard crop for the given world map,
+// with sizes relative to the map as in StandardCrop for the default map.
+func StandardCropFor(worldMap image.Image) *CropOption {
+	b := worldMap.Bounds()
+	return &CropOption{
+		Bound:         int(math.Round(float64(b.Dx()) * 100 / 1920)),
+		MinWidth:      b.Dx() / 3,
+		MinHeight:     b.Dy() / 3,
+		PreserveRatio: true,
+	}
+}
+
 // Coord describes decimal coordinates.
 type Coord struct {
 	// Latitude
@@ -71,12 +211,28 @@ type Coord struct {
 	Long float64
 }
 
+// Valid reports whether latitude is within [-90, 90]
+// and longitude is within [-180, 180].
+func (c Coord) Valid() bool {
+	return c.Lat >= -90 && c.Lat <= 90 && c.Long >= -180 && c.Long <= 180 &&
+		!math.IsNaN(c.Lat) && !math.IsNaN(c.Long)
+}
+
 // Projection is an interface for converting coordinates.
 type Projection interface {
 	// Convert converts coordinates into a point on a map.
 	Convert(coord Coord, mapWidth, mapHeight int) image.Point
 }
 
+// InverseProjection is implemented by projections that can
+// convert points on a map back to coordinates.
+type InverseProjection interface {
+	Projection
+
+	// Unconvert converts a point on a map into coordinates.
+	Unconvert(p image.Point, mapWidth, mapHeight int) Coord
+}
+
 // Mercator provides the Mercator projection.
 var Mercator = mercatorProjection(0)
 
@@ -90,30 +246,88 @@ func (p mercatorProjection) n(lat float64) float64 {
 	return math.Log(math.Tan((math.Pi / 4) + (p.latRad(lat) / 2)))
 }
 
+// mercatorMaxLat is the standard limit of Mercator maps,
+// at which the map of the whole world is square.
+const mercatorMaxLat = 85.05113
+
 func (p mercatorProjection) Convert(c Coord, mapWidth, mapHeight int) image.Point {
 	mw := float64(mapWidth)
 	mh := float64(mapHeight)
+	// Clamp latitude, since n goes to infinity at the poles,
+	// and then to the edges of maps cropped at lower latitudes.
+	lat := math.Max(-mercatorMaxLat, math.Min(mercatorMaxLat, c.Lat))
+	maxN := math.Pi * mh / mw
+	n := math.Max(-maxN, math.Min(maxN, p.n(lat)))
 	fx := (c.Long + 180) * (mw / 360)
-	fy := (mh / 2) - (mw * p.n(c.Lat) / (2 * math.Pi))
+	fy := (mh / 2) - (mw * n / (2 * math.Pi))
 	return image.Point{int(math.Round(fx)), int(math.Round(fy))}
 }
 
+func (p mercatorProjection) Unconvert(pt image.Point, mapWidth, mapHeight int) Coord {
+	mw := float64(mapWidth)
+	mh := float64(mapHeight)
+	n := ((mh / 2) - float64(pt.Y)) * 2 * math.Pi / mw
+	return Coord{
+		Lat:  math.Atan(math.Sinh(n)) * 180 / math.Pi,
+		Long: float64(pt.X)*360/mw - 180,
+	}
+}
+
 // CropOptions defines options for cropping the map image.
+//
+// Negative sizes are treated as zero when rendering,
+// but Validate reports them as ErrInvalidCrop. Without coordinates
+// to crop around, the image is not cropped, and Validate reports
+// ErrEmptyCoords.
 type CropOption struct {
 	// Bound is a minimum distance from the pin to the image boundary.
 	Bound int
 
+	// BoundTop, BoundBottom, BoundLeft, and BoundRight, if not zero,
+	// override Bound for the corresponding side of the image,
+	// for example, to leave more space for overlays on one side.
+	BoundTop, BoundBottom, BoundLeft, BoundRight int
+
 	// MinWidth is a minimum width of image.
 	MinWidth int
 
 	// MinHeight is a minimum height of image.
 	MinHeight int
 
+	// MaxWidth and MaxHeight, if positive, limit the size of the crop:
+	// if the crop covering all pins would be wider or taller,
+	// the image is not cropped. This is useful for overview maps
+	// of pins that may be spread across the world.
+	MaxWidth, MaxHeight int
+
 	// If PreserveRatio is true, the image preserves the ratio between
-	// MinWidth and MinHeight.
-	//
-	// MinHeight must be less than MinWidth for this to work correctly.
+	// MinWidth and MinHeight, which may be either landscape or portrait,
+	// by expanding the crop in the dimension that is too small for it.
+	// The ratio is not preserved if the expanded crop doesn't fit into
+	// the map or if MinWidth or MinHeight is zero.
 	PreserveRatio bool
+
+	// MaxZoomPixelsPerDegree, if positive, limits how tight the crop can
+	// get: the crop always spans at least MinWidth/MaxZoomPixelsPerDegree
+	// degrees of longitude, so that when displayed MinWidth pixels wide,
+	// it doesn't show more than MaxZoomPixelsPerDegree pixels per degree.
+	MaxZoomPixelsPerDegree float64
+
+	// WrapAntimeridian, if true, allows the crop to wrap around the left
+	// and right edges of the map, which are at ±180° longitude for world
+	// maps. When the points are closer to each other across the edges than
+	// within the map, such as points on both sides of the date line
+	// in the Pacific, the map is rolled horizontally to make them adjacent
+	// and the crop tight. The map must cover all longitudes. The crop
+	// rectangle is then in coordinates of the rolled map, see
+	// MapResult.Shift and NewCropLocator.
+	WrapAntimeridian bool
+
+	// FixedBounds, if not nil, makes the crop a fixed geographic window
+	// regardless of pins, which keeps the same area and scale across
+	// renders. Pins outside of the window are not drawn.
+	// Other fields are ignored.
+	FixedBounds *Bounds
 }
 
 // MapPinsProjection returns an image with the given coordinates marked as pins
@@ -125,38 +339,192 @@ type CropOption struct {
 // Pin parts are drawn on top of each other from the bottom of the map to the top
 // by first drawing pinParts[n], then pinParts[n+1], etc.
 // The coordinate point is at the bottom center of each pin part image.
+//
+// Coordinates are not validated: invalid ones are drawn off the map and
+// may result in a broken crop. Use Coord.Valid to check them or
+// RenderChecked to get an error instead.
 func MapPinsProjection(proj Projection, worldMap image.Image, pinParts []image.Image, coords []Coord, crop *CropOption) image.Image {
-	mapWidth := worldMap.Bounds().Max.X
-	mapHeight := worldMap.Bounds().Max.Y
+	return Render(worldMap, pinParts, coords, &Options{Projection: proj, Crop: crop})
+}
 
-	cs := make([]image.Point, len(coords))
+// Pin is a coordinate with its own pin images.
+type Pin struct {
+	Coord
 
-	// Convert coordinates to x, y.
-	for i, c := range coords {
-		cs[i] = proj.Convert(c, mapWidth, mapHeight)
-	}
+	// Parts are pin parts to draw for this pin.
+	// If nil, the default pin parts are used.
+	Parts []image.Image
 
-	// Sort coordinates by latitude so that
-	// lower pins are drawn on top of upper pins.
-	sort.Slice(cs, func(i, j int) bool {
-		return cs[i].Y < cs[j].Y
+	// Anchors define positions of this pin's parts relative to
+	// the point, see Options.Anchors and AnchorsAt. If nil,
+	// the anchors from options are used.
+	Anchors []Anchor
+
+	// Scale, if not zero, resizes this pin's parts and anchors by the
+	// given factor, for example, 2 to draw an important pin twice as
+	// large. The parts stay anchored at the same point.
+	Scale float64
+}
+
+// MapPinsStyled is like MapPinsProjection, but draws each pin with its
+// own pin parts, falling back to defaultParts for pins without them.
+//
+// Pin parts of the same index are drawn for all pins before drawing
+// the next ones, so shadows of all pins are below all pin bodies.
+func MapPinsStyled(proj Projection, worldMap image.Image, pins []Pin, defaultParts []image.Image, crop *CropOption) image.Image {
+	return RenderStyled(worldMap, pins, defaultParts, &Options{Projection: proj, Crop: crop})
+}
+
+// RenderStyled is like MapPinsStyled, but accepts rendering options.
+func RenderStyled(worldMap image.Image, pins []Pin, defaultParts []image.Image, opt *Options) image.Image {
+	coords := make([]Coord, len(pins))
+	for i, p := range pins {
+		coords[i] = p.Coord
+	}
+	// Scale each set of pin parts once for each scale.
+	type scaledKey struct {
+		first *image.Image
+		n     int
+		scale float64
+	}
+	scaled := make(map[scaledKey][]image.Image)
+	m, r := renderMarkers(worldMap, coords, opt, func(i int, pt image.Point) marker {
+		p := pins[i]
+		parts := p.Parts
+		if parts == nil {
+			parts = defaultParts
+		}
+		if p.Scale == 0 || p.Scale == 1 || len(parts) == 0 {
+			return marker{pt: pt, parts: parts, anchors: p.Anchors}
+		}
+		k := scaledKey{&parts[0], len(parts), p.Scale}
+		sp, ok := scaled[k]
+		if !ok {
+			sp = make([]image.Image, len(parts))
+			for j, part := range parts {
+				sp[j] = scaleBy(part, p.Scale)
+			}
+			scaled[k] = sp
+		}
+		anchors := p.Anchors
+		if anchors == nil {
+			// Anchors from options are for unscaled parts.
+			anchors = opt.anchors()
+		}
+		return marker{pt: pt, parts: sp, anchors: scaleAnchors(anchors, p.Scale)}
 	})
+	return finish(m, r, opt)
+}
+
+// MapPinsPositions is like MapPinsProjection, but also returns the position
+// of each coordinate's point relative to the top-left corner of the returned
+// image (that is, its Bounds().Min), for example, to overlay other elements
+// on pins. Positions are returned for all coordinates, even if they are
+// outside of the image.
+func MapPinsPositions(proj Projection, worldMap image.Image, pinParts []image.Image, coords []Coord, crop *CropOption) (image.Image, []image.Point) {
+	m := MapPinsProjection(proj, worldMap, pinParts, coords, crop)
+	pts, _ := layoutPoints(worldMap, coords, &Options{Projection: proj, Crop: crop})
+	for i := range pts {
+		pts[i] = pts[i].Sub(m.Bounds().Min)
+	}
+	return m, pts
+}
+
+// MapResult is a rendered map with the description of its area.
+type MapResult struct {
+	// Image is the rendered image.
+	Image image.Image
+
+	// CropRect is the rectangle of the world map shown in the image,
+	// which is the same as the image bounds. If the map is rolled,
+	// it's in coordinates of the rolled map, see Shift.
+	CropRect image.Rectangle
+
+	// Shift is the number of pixels the world map is rolled horizontally
+	// by with CropOption.WrapAntimeridian: the pixel at x of the world map
+	// is at (x + Shift) mod width of the rolled map.
+	Shift int
+
+	// HasCorners reports whether NorthWest and SouthEast are set,
+	// which requires the projection to be an InverseProjection.
+	HasCorners bool
+
+	// NorthWest and SouthEast are the coordinates of
+	// the top-left and bottom-right corners of CropRect.
+	// If the crop crosses the left and right edges of the rolled
+	// map, SouthEast.Long is less than NorthWest.Long.
+	NorthWest, SouthEast Coord
+}
+
+// MapPinsResult is like MapPinsProjection, but also returns which
+// part of the world map is shown in the image in pixels and, if the
+// projection is an InverseProjection, in geographic coordinates.
+func MapPinsResult(proj Projection, worldMap image.Image, pinParts []image.Image, coords []Coord, crop *CropOption) MapResult {
+	m := MapPinsProjection(proj, worldMap, pinParts, coords, crop)
+	_, l := layoutPoints(worldMap, coords, &Options{Projection: proj, Crop: crop})
+	res := MapResult{Image: m, CropRect: m.Bounds(), Shift: l.shift}
+	if inv, ok := proj.(InverseProjection); ok {
+		w, h := worldMap.Bounds().Dx(), worldMap.Bounds().Dy()
+		// Unroll the corners, keeping the east one to the right
+		// of the west one unless the crop crosses the edge.
+		nw, se := res.CropRect.Min, res.CropRect.Max
+		nw.X = mod(nw.X-res.Shift, w)
+		if se.X = nw.X + res.CropRect.Dx(); se.X > w {
+			se.X -= w
+		}
+		res.HasCorners = true
+		res.NorthWest = inv.Unconvert(nw, w, h)
+		res.SouthEast = inv.Unconvert(se, w, h)
+	}
+	return res
+}
+
+// MapPinsRGBA is like MapPinsProjection, but returns a standalone
+// *image.RGBA with bounds starting at (0, 0), which can be drawn onto
+// without affecting other images. It costs an extra copy of the image.
+func MapPinsRGBA(proj Projection, worldMap image.Image, pinParts []image.Image, coords []Coord, crop *CropOption) *image.RGBA {
+	return copyImage(MapPinsProjection(proj, worldMap, pinParts, coords, crop))
+}
+
+// MapPinsBBox is like MapPinsProjection, but crops the image to the
+// geographic box with the given north-west and south-east corners,
+// clamped to the map, instead of deriving the crop from pins.
+// Pins outside of the box are not drawn.
+func MapPinsBBox(proj Projection, worldMap image.Image, pinParts []image.Image, coords []Coord, nw, se Coord) image.Image {
+	crop := &CropOption{FixedBounds: &Bounds{
+		SW: Coord{se.Lat, nw.Long},
+		NE: Coord{nw.Lat, se.Long},
+	}}
+	return MapPinsProjection(proj, worldMap, pinParts, coords, crop)
+}
 
-	// Draw map.
+// CropRect returns the rectangle of the world map of the given size in the
+// given projection that MapPinsProjection crops the image to for the given
+// coordinates and crop options without rendering it. If crop is nil,
+// returns the rectangle of the whole map. If the map is rolled with
+// CropOption.WrapAntimeridian, the rectangle is in coordinates of the
+// rolled map: use NewCropLocator to locate coordinates in it.
+func CropRect(proj Projection, mapWidth, mapHeight int, coords []Coord, crop *CropOption) image.Rectangle {
+	opt := &Options{Projection: proj, Crop: crop}
+	cs, l := layoutPoints(image.Rect(0, 0, mapWidth, mapHeight), coords, opt)
+	return opt.cropRect(l.visible(coords, cs), l)
+}
+
+// newCanvas returns a new RGBA image with the world map drawn on it.
+func newCanvas(worldMap image.Image) *image.RGBA {
 	m := image.NewRGBA(image.Rect(0, 0, worldMap.Bounds().Dx(), worldMap.Bounds().Dy()))
 	draw.Draw(m, m.Bounds(), worldMap, worldMap.Bounds().Min, draw.Over)
+	return m
+}
 
-	// Draw pin parts.
-	// Looping over pinParts first to better arrange shadows.
-	for _, pin := range pinParts {
-		halfw := pin.Bounds().Dx() / 2
-		h := pin.Bounds().Dy()
-		min := pin.Bounds().Min
-		for _, c := range cs {
-			r := image.Rect(c.X-halfw, c.Y-h, c.X+halfw, c.Y)
-			draw.Draw(m, r, pin, min, draw.Over)
-		}
+// cropRect returns the rectangle of the map of the given size
+// that contains the given points according to crop options.
+func cropRect(cs []image.Point, mapWidth, mapHeight int, crop *CropOption) image.Rectangle {
+	if len(cs) == 0 {
+		// Nothing to crop around.
+		return image.Rect(0, 0, mapWidth, mapHeight)
 	}
+	crop = crop.clamped()
 
 	// Calculate min&max values.
 	maxX := 0
@@ -177,63 +545,133 @@ func MapPinsProjection(proj Projection, worldMap image.Image, pinParts []image.I
 			maxY = c.Y
 		}
 	}
-	if crop == nil {
-		return m
-	}
 
 	// Calculate bounds.
-	minX -= crop.Bound
+	minX -= crop.side(crop.BoundLeft)
 	if minX < 0 {
 		minX = 0
 	}
-	minY -= crop.Bound
+	minY -= crop.side(crop.BoundTop)
 	if minY < 0 {
 		minY = 0
 	}
-	maxX += crop.Bound
+	maxX += crop.side(crop.BoundRight)
 	if maxX > mapWidth {
 		maxX = mapWidth
 	}
-	maxY += crop.Bound
+	maxY += crop.side(crop.BoundBottom)
 	if maxY > mapHeight {
-		maxX = mapHeight
+		maxY = mapHeight
 	}
 
-	w := maxX - minX
-	if w < crop.MinWidth {
-		minX -= (crop.MinWidth - w) / 2
-		add := 0
-		if minX < 0 {
-			add = -minX
-			minX = 0
+	minX, maxX = expandRange(minX, maxX, crop.minWidth(mapWidth), mapWidth)
+	minY, maxY = expandRange(minY, maxY, crop.MinHeight, mapHeight)
+	if crop.PreserveRatio && crop.MinWidth > 0 && crop.MinHeight > 0 {
+		// Expand the dimension that is too small for the ratio.
+		ratio := float64(crop.MinWidth) / float64(crop.MinHeight)
+		w, h := maxX-minX, maxY-minY
+		if float64(w) < float64(h)*ratio {
+			minX, maxX = expandRange(minX, maxX, int(math.Round(float64(h)*ratio)), mapWidth)
+		} else {
+			minY, maxY = expandRange(minY, maxY, int(math.Round(float64(w)/ratio)), mapHeight)
 		}
-		maxX += (crop.MinWidth-w)/2 + add
-		if maxX > mapWidth {
-			maxX = mapWidth
+	}
+
+	if (crop.MaxWidth > 0 && maxX-minX > crop.MaxWidth) || (crop.MaxHeight > 0 && maxY-minY > crop.MaxHeight) {
+		// The crop is too large, show the whole map.
+		return image.Rect(0, 0, mapWidth, mapHeight)
+	}
+
+	// Don't return empty rectangles, for example, for a single point
+	// with zero bound and sizes.
+	if maxX == minX {
+		if maxX < mapWidth {
+			maxX++
+		} else {
+			minX--
 		}
 	}
-	w = maxX - minX
-	minHeight := 0
-	if crop.PreserveRatio {
-		minHeight = int((float64(crop.MinHeight) / float64(crop.MinWidth)) * float64(w))
+	if maxY == minY {
+		if maxY < mapHeight {
+			maxY++
+		} else {
+			minY--
+		}
 	}
-	if minHeight < crop.MinHeight {
-		minHeight = crop.MinHeight
+	return image.Rect(minX, minY, maxX, maxY)
+}
+
+// expandRange expands the range from min to max around its center to
+// the given size, moving it to stay within the range from 0 to limit
+// and shrinking it to this range if it doesn't fit.
+func expandRange(min, max, size, limit int) (int, int) {
+	d := size - (max - min)
+	if d <= 0 {
+		return min, max
+	}
+	min -= d / 2
+	max += d - d/2
+	if min < 0 {
+		max -= min
+		min = 0
 	}
-	h := maxY - minY
-	if h < minHeight {
-		minY -= (minHeight - h) / 2
-		add := 0
-		if minY < 0 {
-			add = -minY
-			minY = 0
+	if max > limit {
+		min -= max - limit
+		max = limit
+		if min < 0 {
+			min = 0
 		}
-		maxY += (minHeight-h)/2 + add
-		if maxY > mapHeight {
-			maxY = mapHeight
+	}
+	return min, max
+}
+
+// side returns the bound of the side of the image,
+// which is the given value if it's not zero or Bound.
+func (crop *CropOption) side(bound int) int {
+	if bound != 0 {
+		return bound
+	}
+	return crop.Bound
+}
+
+// clamped returns a copy of the crop option with negative values replaced by zero.
+func (crop *CropOption) clamped() *CropOption {
+	c := *crop
+	for _, b := range []*int{&c.Bound, &c.BoundTop, &c.BoundBottom, &c.BoundLeft, &c.BoundRight} {
+		if *b < 0 {
+			*b = 0
+		}
+	}
+	if c.MinWidth < 0 {
+		c.MinWidth = 0
+	}
+	if c.MinHeight < 0 {
+		c.MinHeight = 0
+	}
+	if c.MaxWidth < 0 {
+		c.MaxWidth = 0
+	}
+	if c.MaxHeight < 0 {
+		c.MaxHeight = 0
+	}
+	if c.MaxZoomPixelsPerDegree < 0 {
+		c.MaxZoomPixelsPerDegree = 0
+	}
+	return &c
+}
+
+// minWidth returns the minimum width of the crop
+// on the map of the given width.
+func (crop *CropOption) minWidth(mapWidth int) int {
+	w := crop.MinWidth
+	if crop.MaxZoomPixelsPerDegree > 0 {
+		// Degrees of longitude the crop must span to stay within the max zoom.
+		deg := float64(crop.MinWidth) / crop.MaxZoomPixelsPerDegree
+		if zw := int(math.Ceil(deg * float64(mapWidth) / 360)); zw > w {
+			w = zw
 		}
 	}
-	return m.SubImage(image.Rect(minX, minY, maxX, maxY))
+	return w
 }
 
 // MapPins is like MapPinsProjection with Mercator projection.
@@ -1,9 +1,14 @@
 package onmap_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
 	"image/png"
+	"math"
 	"os"
 	"testing"
 
@@ -46,6 +51,440 @@ func TestPins(t *testing.T) {
 	fmt.Println("Test images are written, check them :)")
 }
 
+func TestCoordValid(t *testing.T) {
+	valid := []onmap.Coord{{0, 0}, {90, 180}, {-90, -180}, {42.1, 19.1}}
+	for _, c := range valid {
+		if !c.Valid() {
+			t.Errorf("expected %v to be valid", c)
+		}
+	}
+	invalid := []onmap.Coord{{200, 500}, {90.1, 0}, {0, -180.1}, {math.NaN(), 0}}
+	for _, c := range invalid {
+		if c.Valid() {
+			t.Errorf("expected %v to be invalid", c)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		if err := onmap.Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b := onmap.DefaultMap().Bounds(); b.Empty() {
+		t.Fatal("expected non-empty default map")
+	}
+	if n := len(onmap.DefaultPin()); n != 2 {
+		t.Fatalf("expected 2 default pin parts, got %d", n)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	b := onmap.DefaultMap().Bounds()
+	if b.Dx() != 1920 || b.Dy() != 1629 {
+		t.Errorf("expected 1920x1629 default map, got %v", b.Size())
+	}
+	if b.Dx() < onmap.StandardCrop.MinWidth || b.Dy() < onmap.StandardCrop.MinHeight {
+		t.Errorf("default map %v is smaller than the standard crop", b.Size())
+	}
+
+	parts := onmap.DefaultPin()
+	for i, m := range []image.Image{onmap.PinShadowImage(), onmap.PinImage()} {
+		if m.Bounds().Size() != parts[i].Bounds().Size() {
+			t.Errorf("part %d: expected size %v, got %v", i, parts[i].Bounds().Size(), m.Bounds().Size())
+		}
+	}
+	// Modifying the copy doesn't change the default pin.
+	pin := onmap.PinImage().(draw.Image)
+	c := parts[1].At(31, 10)
+	pin.Set(31, 10, color.Transparent)
+	if got := onmap.DefaultPin()[1].At(31, 10); got != c {
+		t.Errorf("expected default pin to be unchanged, got %v", got)
+	}
+}
+
+func TestStandardCropFor(t *testing.T) {
+	if crop := onmap.StandardCropFor(onmap.DefaultMap()); *crop != *onmap.StandardCrop {
+		t.Errorf("expected %+v for the default map, got %+v", *onmap.StandardCrop, *crop)
+	}
+	crop := onmap.StandardCropFor(image.NewRGBA(image.Rect(0, 0, 3840, 3258)))
+	want := onmap.CropOption{Bound: 200, MinWidth: 1280, MinHeight: 1086, PreserveRatio: true}
+	if *crop != want {
+		t.Errorf("expected %+v for the 2x map, got %+v", want, *crop)
+	}
+}
+
+func TestPinsDeterministic(t *testing.T) {
+	// Pins on the same latitude overlapping each other.
+	var coords []onmap.Coord
+	for i := 0; i < 20; i++ {
+		coords = append(coords, onmap.Coord{Lat: 42.1, Long: 19.1 + float64(i%7)*0.3})
+	}
+	encode := func(coords []onmap.Coord) []byte {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, onmap.Pins(coords, onmap.StandardCrop)); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	if !bytes.Equal(encode(coords), encode(coords)) {
+		t.Fatal("rendering the same coordinates produced different images")
+	}
+
+	// Pins with tied latitudes are ordered regardless of the input order.
+	reversed := make([]onmap.Coord, len(coords))
+	for i, c := range coords {
+		reversed[len(coords)-1-i] = c
+	}
+	if !bytes.Equal(encode(coords), encode(reversed)) {
+		t.Fatal("rendering coordinates in a different order produced different images")
+	}
+}
+
+func TestCustomMapSize(t *testing.T) {
+	coords := []onmap.Coord{{0, 0}}
+	worldMap := solidMap(2000, 1000, color.White)
+	m := onmap.MapPins(worldMap, onmap.DefaultPin(), coords, nil)
+	if b := m.Bounds(); b.Dx() != 2000 || b.Dy() != 1000 {
+		t.Fatalf("expected 2000x1000 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	// Map with bounds not starting at zero.
+	sub := solidMap(2100, 1100, color.White).SubImage(image.Rect(100, 100, 2100, 1100))
+	m = onmap.MapPins(sub, onmap.DefaultPin(), coords, nil)
+	if b := m.Bounds(); b.Dx() != 2000 || b.Dy() != 1000 {
+		t.Fatalf("expected 2000x1000 image, got %dx%d", b.Dx(), b.Dy())
+	}
+	// Pin tip must be at the center.
+	if c := m.At(1000, 499); sameColor(c, color.White) {
+		t.Fatalf("expected pin at the center of the map")
+	}
+}
+
+func TestMapPinsStyled(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	pins := []onmap.Pin{
+		{Coord: onmap.Coord{0, -90}, Parts: []image.Image{solidMap(10, 10, red)}},
+		{Coord: onmap.Coord{0, 90}},
+	}
+	m := onmap.MapPinsStyled(onmap.Mercator, worldMap, pins, []image.Image{solidMap(10, 10, blue)}, nil)
+	for i, want := range []color.Color{red, blue} {
+		p := onmap.Mercator.Convert(pins[i].Coord, 360, 360)
+		if c := m.At(p.X, p.Y-1); !sameColor(c, want) {
+			t.Errorf("pin %d: expected %v, got %v", i, want, c)
+		}
+	}
+}
+
+func TestRenderStyledInputOrder(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	// The blue pin is higher on the map, but specified last.
+	pins := []onmap.Pin{
+		{Coord: onmap.Coord{0, 0}, Parts: []image.Image{solidMap(20, 20, red)}},
+		{Coord: onmap.Coord{3, 0}, Parts: []image.Image{solidMap(20, 20, blue)}},
+	}
+	p := onmap.Mercator.Convert(pins[0].Coord, 360, 360)
+
+	m := onmap.RenderStyled(worldMap, pins, nil, nil)
+	if c := m.At(p.X, p.Y-10); !sameColor(c, red) {
+		t.Errorf("expected lower pin on top by default, got %v", c)
+	}
+	m = onmap.RenderStyled(worldMap, pins, nil, &onmap.Options{InputOrder: true})
+	if c := m.At(p.X, p.Y-10); !sameColor(c, blue) {
+		t.Errorf("expected last pin on top with InputOrder, got %v", c)
+	}
+}
+
+func TestRenderCenterOrder(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	central := onmap.Pin{Coord: onmap.Coord{0, 0}, Parts: []image.Image{solidMap(20, 20, red)}}
+	pins := []onmap.Pin{central}
+	for _, c := range []onmap.Coord{{5, 0}, {-5, 0}, {0, 5}, {0, -5}, {4, 4}, {-4, -4}, {-4, 4}, {4, -4}} {
+		pins = append(pins, onmap.Pin{Coord: c, Parts: []image.Image{solidMap(20, 20, blue)}})
+	}
+	p := onmap.Mercator.Convert(central.Coord, 360, 360)
+	r := image.Rect(p.X-10, p.Y-20, p.X+10, p.Y)
+
+	m := onmap.RenderStyled(worldMap, pins, nil, nil)
+	if n := countColor(m.(*image.RGBA).SubImage(r), red); n == r.Dx()*r.Dy() {
+		t.Errorf("expected central pin overdrawn by lower neighbors by default")
+	}
+	m = onmap.RenderStyled(worldMap, pins, nil, &onmap.Options{CenterOrder: true})
+	if n := countColor(m.(*image.RGBA).SubImage(r), red); n != r.Dx()*r.Dy() {
+		t.Errorf("expected central pin on top with CenterOrder, got %d of %d pixels", n, r.Dx()*r.Dy())
+	}
+
+	// The centroid includes pins outside of the crop: far western
+	// pins make the western of two overlapping pins more central.
+	west := onmap.Pin{Coord: onmap.Coord{0, 0}, Parts: []image.Image{solidMap(20, 20, red)}}
+	east := onmap.Pin{Coord: onmap.Coord{0, 4}, Parts: []image.Image{solidMap(20, 20, blue)}}
+	pins = []onmap.Pin{west, east}
+	for i := 0; i < 4; i++ {
+		pins = append(pins, onmap.Pin{Coord: onmap.Coord{0, -150}, Parts: []image.Image{solidMap(20, 20, blue)}})
+	}
+	crop := &onmap.CropOption{FixedBounds: &onmap.Bounds{SW: onmap.Coord{-20, -20}, NE: onmap.Coord{20, 20}}}
+	m = onmap.RenderStyled(worldMap, pins, nil, &onmap.Options{Crop: crop, CenterOrder: true})
+	q := onmap.Mercator.Convert(east.Coord, 360, 360)
+	overlap := image.Rect(q.X-10, q.Y-20, p.X+10, p.Y)
+	if n := countColor(m.(*image.RGBA).SubImage(overlap), red); n != overlap.Dx()*overlap.Dy() {
+		t.Errorf("expected western pin on top, got %d of %d pixels", n, overlap.Dx()*overlap.Dy())
+	}
+}
+
+func TestRenderStyledScale(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	pins := []onmap.Pin{
+		{Coord: onmap.Coord{0, -90}, Parts: []image.Image{solidMap(10, 20, red)}},
+		{Coord: onmap.Coord{0, 90}, Parts: []image.Image{solidMap(10, 20, blue)}, Scale: 2},
+	}
+	m := onmap.RenderStyled(worldMap, pins, nil, nil)
+	small, large := countColor(m, red), countColor(m, blue)
+	if small == 0 || large < 4*small*9/10 || large > 4*small*11/10 {
+		t.Errorf("expected the large pin to be 4 times larger, got %d and %d pixels", small, large)
+	}
+
+	// The large pin is still anchored at the bottom center.
+	p := onmap.Mercator.Convert(pins[1].Coord, 360, 360)
+	if c := m.At(p.X, p.Y-39); !sameColor(c, blue) {
+		t.Errorf("expected large pin above the point, got %v", c)
+	}
+	if c := m.At(p.X, p.Y); !sameColor(c, color.White) {
+		t.Errorf("expected no pin below the point, got %v", c)
+	}
+
+	// Anchors from options are scaled with the pin: it's centered
+	// at the point, extending 20 pixels below it.
+	opt := &onmap.Options{Anchors: onmap.AnchorsAt(pins[1].Parts, 0.5, 0.5)}
+	m = onmap.RenderStyled(worldMap, pins, nil, opt)
+	if c := m.At(p.X, p.Y+15); !sameColor(c, blue) {
+		t.Errorf("expected large pin centered at the point, got %v below it", c)
+	}
+	if c := m.At(p.X, p.Y+25); !sameColor(c, color.White) {
+		t.Errorf("expected large pin centered at the point, got %v far below it", c)
+	}
+}
+
+func TestMapPinsResult(t *testing.T) {
+	coords := []onmap.Coord{
+		{51.5, -0.1}, // London
+		{52.5, 13.4}, // Berlin
+	}
+	res := onmap.MapPinsResult(onmap.Mercator, onmap.DefaultMap(), onmap.DefaultPin(), coords, onmap.StandardCrop)
+	if res.CropRect != res.Image.Bounds() {
+		t.Errorf("expected crop rect %v, got %v", res.Image.Bounds(), res.CropRect)
+	}
+	if !res.HasCorners {
+		t.Fatalf("expected corners for Mercator")
+	}
+	for _, c := range coords {
+		if c.Lat > res.NorthWest.Lat || c.Lat < res.SouthEast.Lat ||
+			c.Long < res.NorthWest.Long || c.Long > res.SouthEast.Long {
+			t.Errorf("%v is outside of corners %v, %v", c, res.NorthWest, res.SouthEast)
+		}
+	}
+
+	// Projections without inverse have no corners.
+	proj := onmap.Extent(onmap.Mercator, onmap.Coord{60, -10}, onmap.Coord{40, 20})
+	res = onmap.MapPinsResult(proj, solidMap(600, 400, color.White), nil, coords, onmap.StandardCrop)
+	if res.CropRect != res.Image.Bounds() {
+		t.Errorf("expected crop rect %v, got %v", res.Image.Bounds(), res.CropRect)
+	}
+	if res.HasCorners {
+		t.Errorf("expected no corners for projection without inverse")
+	}
+}
+
+func TestMapPinsPositions(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{41.9097306, 12.2558141}, // Rome
+		{-31.952222, 115.858889}, // Perth, outside of the crop
+	}
+	worldMap := onmap.DefaultMap()
+	crop := &onmap.CropOption{Bound: 50}
+	m, pts := onmap.MapPinsPositions(onmap.Mercator, worldMap, onmap.DefaultPin(), coords[:2], crop)
+	if len(pts) != 2 {
+		t.Fatalf("expected 2 positions, got %d", len(pts))
+	}
+	b := worldMap.Bounds()
+	for i, c := range coords[:2] {
+		want := onmap.Mercator.Convert(c, b.Dx(), b.Dy()).Sub(m.Bounds().Min)
+		if pts[i] != want {
+			t.Errorf("%v: expected %v, got %v", c, want, pts[i])
+		}
+		if !pts[i].In(image.Rectangle{Max: m.Bounds().Size()}) {
+			t.Errorf("%v: position %v is outside of the image", c, pts[i])
+		}
+	}
+
+	// Coordinates outside of the fixed crop are reported too.
+	crop = &onmap.CropOption{FixedBounds: &onmap.Bounds{SW: onmap.Coord{35, 5}, NE: onmap.Coord{50, 25}}}
+	m, pts = onmap.MapPinsPositions(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, crop)
+	if len(pts) != 3 {
+		t.Fatalf("expected 3 positions, got %d", len(pts))
+	}
+	if pts[2].In(image.Rectangle{Max: m.Bounds().Size()}) {
+		t.Errorf("expected position %v outside of the image", pts[2])
+	}
+}
+
+func TestMapPinsRGBA(t *testing.T) {
+	coords := []onmap.Coord{{42.1, 19.1}, {41.9097306, 12.2558141}}
+	m := onmap.MapPinsRGBA(onmap.Mercator, onmap.DefaultMap(), onmap.DefaultPin(), coords, onmap.StandardCrop)
+	expected := onmap.MapPinsProjection(onmap.Mercator, onmap.DefaultMap(), onmap.DefaultPin(), coords, onmap.StandardCrop)
+	b := expected.Bounds()
+	if m.Bounds() != image.Rect(0, 0, b.Dx(), b.Dy()) {
+		t.Fatalf("expected bounds at origin of size %v, got %v", b.Size(), m.Bounds())
+	}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if c1, c2 := m.At(x, y), expected.At(b.Min.X+x, b.Min.Y+y); !sameColor(c1, c2) {
+				t.Fatalf("expected %v at (%d, %d), got %v", c2, x, y, c1)
+			}
+		}
+	}
+}
+
+func TestMapPinsBBox(t *testing.T) {
+	coords := []onmap.Coord{
+		{45.4628329, 9.1076924},  // Milano
+		{-31.952222, 115.858889}, // Perth
+	}
+	nw := onmap.Coord{55, 5}
+	se := onmap.Coord{45, 20}
+	worldMap := onmap.DefaultMap()
+	m := onmap.MapPinsBBox(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, nw, se)
+	b := worldMap.Bounds()
+	p1 := onmap.Mercator.Convert(nw, b.Dx(), b.Dy())
+	p2 := onmap.Mercator.Convert(se, b.Dx(), b.Dy())
+	if size := p2.Sub(p1); m.Bounds().Size() != size {
+		t.Fatalf("expected size %v, got %v", size, m.Bounds().Size())
+	}
+
+	// Box extending beyond the map is clamped.
+	m = onmap.MapPinsBBox(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, onmap.Coord{89, -180}, onmap.Coord{0, 0})
+	if !m.Bounds().In(b) {
+		t.Fatalf("crop %v is outside of map %v", m.Bounds(), b)
+	}
+}
+
+func TestCropNearSouthEdge(t *testing.T) {
+	mb := onmap.DefaultMap().Bounds()
+	for _, c := range []onmap.Coord{{-60, 10}, {-80, 10}} {
+		m := onmap.Pins([]onmap.Coord{c}, onmap.StandardCrop)
+		b := m.Bounds()
+		if !b.In(mb) {
+			t.Fatalf("%v: crop %v is outside of map %v", c, b, mb)
+		}
+		if b.Dx() != onmap.StandardCrop.MinWidth {
+			t.Fatalf("%v: expected width %d, got %d", c, onmap.StandardCrop.MinWidth, b.Dx())
+		}
+	}
+}
+
+func TestCropRect(t *testing.T) {
+	worldMap := onmap.DefaultMap()
+	w, h := worldMap.Bounds().Dx(), worldMap.Bounds().Dy()
+	bar := onmap.Coord{42.1, 19.1}
+	rome := onmap.Coord{41.9097306, 12.2558141}
+	tokyo := onmap.Coord{35.6895, 139.6917}
+	tests := []struct {
+		name   string
+		coords []onmap.Coord
+		crop   *onmap.CropOption
+	}{
+		{"single", []onmap.Coord{bar}, &onmap.CropOption{Bound: 50}},
+		{"multiple", []onmap.Coord{bar, rome, tokyo}, &onmap.CropOption{Bound: 20}},
+		{"min width", []onmap.Coord{bar, rome}, onmap.StandardCrop},
+		{"no crop", []onmap.Coord{bar}, nil},
+	}
+	for _, tt := range tests {
+		r := onmap.CropRect(onmap.Mercator, w, h, tt.coords, tt.crop)
+		m := onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), tt.coords, tt.crop)
+		if r != m.Bounds() {
+			t.Errorf("%s: expected %v, got %v", tt.name, m.Bounds(), r)
+		}
+	}
+
+	p := onmap.Mercator.Convert(bar, w, h)
+	if r := onmap.CropRect(onmap.Mercator, w, h, []onmap.Coord{bar}, &onmap.CropOption{Bound: 50}); r != image.Rect(p.X-50, p.Y-50, p.X+50, p.Y+50) {
+		t.Errorf("expected 100x100 rectangle around %v, got %v", p, r)
+	}
+	if r := onmap.CropRect(onmap.Mercator, w, h, []onmap.Coord{bar, rome}, onmap.StandardCrop); r.Dx() != 640 || r.Dy() != 543 {
+		t.Errorf("expected crop expanded to 640x543, got %v", r.Size())
+	}
+}
+
+func TestCropAsymmetricBounds(t *testing.T) {
+	worldMap := solidMap(1000, 1000, color.White)
+	coords := []onmap.Coord{{10, -20}, {-10, 20}}
+	crop := &onmap.CropOption{Bound: 10, BoundRight: 200, BoundTop: 30}
+	r := onmap.CropRect(onmap.Mercator, 1000, 1000, coords, crop)
+	nw := onmap.Mercator.Convert(coords[0], 1000, 1000)
+	se := onmap.Mercator.Convert(coords[1], 1000, 1000)
+	want := image.Rect(nw.X-10, nw.Y-30, se.X+200, se.Y+10)
+	if r != want {
+		t.Errorf("expected %v, got %v", want, r)
+	}
+	if m := onmap.MapPinsProjection(onmap.Mercator, worldMap, nil, coords, crop); m.Bounds() != want {
+		t.Errorf("expected image bounds %v, got %v", want, m.Bounds())
+	}
+}
+
+func TestCropPreserveRatio(t *testing.T) {
+	worldMap := solidMap(1000, 1000, color.White)
+	tests := []struct {
+		name   string
+		coords []onmap.Coord
+		crop   *onmap.CropOption
+	}{
+		{"portrait", []onmap.Coord{{10, 0}, {10, 60}}, &onmap.CropOption{Bound: 10, MinWidth: 100, MinHeight: 200, PreserveRatio: true}},
+		{"landscape", []onmap.Coord{{50, 10}, {-20, 10}}, &onmap.CropOption{Bound: 10, MinWidth: 300, MinHeight: 100, PreserveRatio: true}},
+		{"portrait tall points", []onmap.Coord{{60, 0}, {-60, 5}}, &onmap.CropOption{Bound: 10, MinWidth: 100, MinHeight: 200, PreserveRatio: true}},
+		{"landscape wide points", []onmap.Coord{{0, -100}, {5, 100}}, &onmap.CropOption{Bound: 10, MinWidth: 300, MinHeight: 100, PreserveRatio: true}},
+	}
+	for _, tt := range tests {
+		m := onmap.MapPinsProjection(onmap.Mercator, worldMap, nil, tt.coords, tt.crop)
+		s := m.Bounds().Size()
+		if s.X < tt.crop.MinWidth || s.Y < tt.crop.MinHeight {
+			t.Errorf("%s: expected at least %dx%d, got %v", tt.name, tt.crop.MinWidth, tt.crop.MinHeight, s)
+		}
+		// Compare ratios within rounding.
+		if d := s.X*tt.crop.MinHeight - s.Y*tt.crop.MinWidth; abs(d) > tt.crop.MinWidth {
+			t.Errorf("%s: expected %d:%d ratio, got %v", tt.name, tt.crop.MinWidth, tt.crop.MinHeight, s)
+		}
+	}
+}
+
+func TestCropMaxZoom(t *testing.T) {
+	coords := []onmap.Coord{{41.9097306, 12.2558141}} // Rome
+	crop := &onmap.CropOption{
+		Bound:                  10,
+		MinWidth:               200,
+		MinHeight:              100,
+		PreserveRatio:          true,
+		MaxZoomPixelsPerDegree: 2,
+	}
+	m := onmap.Pins(coords, crop)
+	mapWidth := onmap.DefaultMap().Bounds().Dx()
+	deg := float64(m.Bounds().Dx()) * 360 / float64(mapWidth)
+	if zoom := float64(crop.MinWidth) / deg; zoom > crop.MaxZoomPixelsPerDegree {
+		t.Fatalf("zoom %.2f px/deg exceeds max %.2f", zoom, crop.MaxZoomPixelsPerDegree)
+	}
+	if m.Bounds().Dx() <= crop.MinWidth {
+		t.Fatalf("expected crop wider than MinWidth %d, got %d", crop.MinWidth, m.Bounds().Dx())
+	}
+}
+
 func writePng(filename string, m image.Image) error {
 	f, err := os.Create(filename)
 	if err != nil {
@@ -57,3 +496,103 @@ func writePng(filename string, m image.Image) error {
 	}
 	return nil
 }
+
+func solidMap(w, h int, c color.Color) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(m, m.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return m
+}
+
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+func TestSetDefaultAssets(t *testing.T) {
+	if err := onmap.SetDefaultAssets(nil, nil, nil); err == nil {
+		t.Fatalf("expected error for nil images")
+	}
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	if err := onmap.SetDefaultAssets(worldMap, solidMap(10, 10, red), solidMap(12, 4, gray)); err != nil {
+		t.Fatal(err)
+	}
+	defer onmap.ResetDefaultAssets()
+
+	m := onmap.Pins([]onmap.Coord{{0, 0}}, nil)
+	if m.Bounds() != worldMap.Bounds() {
+		t.Fatalf("expected the synthetic map bounds %v, got %v", worldMap.Bounds(), m.Bounds())
+	}
+	if c := m.At(180, 175); !sameColor(c, red) {
+		t.Errorf("expected synthetic pin, got %v", c)
+	}
+	if c := m.At(174, 178); !sameColor(c, gray) {
+		t.Errorf("expected synthetic shadow, got %v", c)
+	}
+	if c := m.At(10, 10); !sameColor(c, color.White) {
+		t.Errorf("expected synthetic map, got %v", c)
+	}
+	if b := onmap.BaseAtWidth(180).Bounds(); b.Dx() != 180 || b.Dy() != 180 {
+		t.Errorf("expected base of the synthetic map, got %v", b)
+	}
+
+	onmap.ResetDefaultAssets()
+	if b := onmap.DefaultMap().Bounds(); b.Dx() != 1920 {
+		t.Errorf("expected the embedded map after reset, got %v", b)
+	}
+}
+
+func TestPinsEmptyCoords(t *testing.T) {
+	b := onmap.DefaultMap().Bounds()
+	for _, crop := range []*onmap.CropOption{onmap.StandardCrop, {Bound: 10}, {MinWidth: 300, MinHeight: 200, PreserveRatio: true}} {
+		m := onmap.Pins(nil, crop)
+		if m.Bounds().Size() != b.Size() {
+			t.Errorf("crop %v: expected uncropped %v image, got %v", crop, b.Size(), m.Bounds())
+		}
+		if r := onmap.CropRect(onmap.Mercator, b.Dx(), b.Dy(), []onmap.Coord{}, crop); r.Size() != b.Size() {
+			t.Errorf("crop %v: expected whole map rectangle, got %v", crop, r)
+		}
+	}
+	if err := onmap.Validate(onmap.DefaultMap(), nil, &onmap.Options{Crop: onmap.StandardCrop}); !errors.Is(err, onmap.ErrEmptyCoords) {
+		t.Errorf("expected ErrEmptyCoords, got %v", err)
+	}
+}
+
+func TestCropMaxSize(t *testing.T) {
+	worldMap := onmap.DefaultMap()
+	w, h := worldMap.Bounds().Dx(), worldMap.Bounds().Dy()
+	bar := onmap.Coord{42.1, 19.1}
+	rome := onmap.Coord{41.9097306, 12.2558141}
+	tokyo := onmap.Coord{35.6895, 139.6917}
+	limited := &onmap.CropOption{Bound: 20, MaxWidth: 400, MaxHeight: 300}
+
+	// Pins in separate groups fit into the maximum size.
+	coords := []onmap.Coord{bar, rome}
+	tight := onmap.CropRect(onmap.Mercator, w, h, coords, &onmap.CropOption{Bound: 20})
+	r := onmap.CropRect(onmap.Mercator, w, h, coords, limited)
+	if r != tight {
+		t.Errorf("fits: expected tight crop %v, got %v", tight, r)
+	}
+	if r.Dx() > 400 || r.Dy() > 300 {
+		t.Errorf("fits: expected crop within 400x300, got %v", r.Size())
+	}
+	m := onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, limited)
+	if m.Bounds() != r {
+		t.Errorf("fits: expected image bounds %v, got %v", r, m.Bounds())
+	}
+
+	// The crop for pins across the world exceeds the maximum size.
+	coords = []onmap.Coord{bar, rome, tokyo}
+	if r := onmap.CropRect(onmap.Mercator, w, h, coords, limited); r != worldMap.Bounds() {
+		t.Errorf("exceeds: expected whole map %v, got %v", worldMap.Bounds(), r)
+	}
+	m = onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, limited)
+	if m.Bounds() != worldMap.Bounds() {
+		t.Errorf("exceeds: expected whole map %v, got %v", worldMap.Bounds(), m.Bounds())
+	}
+	if m := onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, &onmap.CropOption{Bound: 20, MaxWidth: 400}); m.Bounds() != worldMap.Bounds() {
+		t.Errorf("exceeds width: expected whole map, got %v", m.Bounds())
+	}
+}
@@ -0,0 +1,23 @@
+package onmap
+
+import "image"
+
+// OverlapCount returns the number of pairs of pins drawn with the given
+// pin parts at the given points whose bounding rectangles intersect.
+// It's useful as a quality metric, for example, to choose
+// a clustering radius that keeps overlaps below a threshold.
+func OverlapCount(points []image.Point, parts []image.Image) int {
+	rects := make([]image.Rectangle, len(points))
+	for i, p := range points {
+		rects[i] = pinRect(p, parts)
+	}
+	n := 0
+	for i := range rects {
+		for j := i + 1; j < len(rects); j++ {
+			if rects[i].Overlaps(rects[j]) {
+				n++
+			}
+		}
+	}
+	return n
+}
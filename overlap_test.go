@@ -0,0 +1,27 @@
+package onmap_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestOverlapCount(t *testing.T) {
+	parts := onmap.DefaultPin()
+	w := parts[0].Bounds().Dx()
+	tests := []struct {
+		points   []image.Point
+		expected int
+	}{
+		{nil, 0},
+		{[]image.Point{{100, 100}, {100 + w, 100}, {100, 200}}, 0},
+		{[]image.Point{{100, 100}, {110, 105}, {300, 300}}, 1},
+		{[]image.Point{{100, 100}, {101, 100}, {102, 100}}, 3},
+	}
+	for i, tt := range tests {
+		if n := onmap.OverlapCount(tt.points, parts); n != tt.expected {
+			t.Errorf("%d: expected %d overlaps, got %d", i, tt.expected, n)
+		}
+	}
+}
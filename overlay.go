@@ -0,0 +1,64 @@
+package onmap
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// DrawGeoImage draws the source image, which covers the given geographic
+// bounds, onto dst, which is a world map in the given projection.
+// The source image is resampled (using nearest neighbor) so that
+// each of its pixels lands on the map where its coordinates are.
+// This is useful for overlaying georeferenced images, such as weather
+// radar tiles, onto the map.
+//
+// The source image must be in equirectangular (plate carrée) projection
+// and the map projection must be cylindrical, that is, x must depend only
+// on longitude and y only on latitude, like in Mercator.
+func DrawGeoImage(dst draw.Image, proj Projection, src image.Image, bounds Bounds) {
+	db := dst.Bounds()
+	mapWidth, mapHeight := db.Dx(), db.Dy()
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	if sw == 0 || sh == 0 {
+		return
+	}
+
+	min := proj.Convert(Coord{bounds.NE.Lat, bounds.SW.Long}, mapWidth, mapHeight)
+	max := proj.Convert(Coord{bounds.SW.Lat, bounds.NE.Long}, mapWidth, mapHeight)
+	r := image.Rect(min.X, min.Y, max.X, max.Y).Intersect(image.Rect(0, 0, mapWidth, mapHeight))
+	if r.Empty() {
+		return
+	}
+
+	dLat := (bounds.NE.Lat - bounds.SW.Lat) / float64(sh)
+	dLong := (bounds.NE.Long - bounds.SW.Long) / float64(sw)
+	midLat := (bounds.NE.Lat + bounds.SW.Lat) / 2
+	midLong := (bounds.NE.Long + bounds.SW.Long) / 2
+
+	// Find source rows and columns for each destination pixel: the first
+	// row (column) whose bottom (right) edge is projected past the pixel.
+	rows := make([]int, r.Dy())
+	for y := range rows {
+		rows[y] = sort.Search(sh-1, func(j int) bool {
+			lat := bounds.NE.Lat - float64(j+1)*dLat
+			return proj.Convert(Coord{lat, midLong}, mapWidth, mapHeight).Y > r.Min.Y+y
+		})
+	}
+	cols := make([]int, r.Dx())
+	for x := range cols {
+		cols[x] = sort.Search(sw-1, func(i int) bool {
+			long := bounds.SW.Long + float64(i+1)*dLong
+			return proj.Convert(Coord{midLat, long}, mapWidth, mapHeight).X > r.Min.X+x
+		})
+	}
+
+	warped := image.NewRGBA(r)
+	for y, j := range rows {
+		for x, i := range cols {
+			warped.Set(r.Min.X+x, r.Min.Y+y, src.At(sb.Min.X+i, sb.Min.Y+j))
+		}
+	}
+	draw.Draw(dst, r.Add(db.Min), warped, r.Min, draw.Over)
+}
@@ -0,0 +1,32 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestDrawGeoImage(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	m := solidMap(360, 360, color.White)
+	tile := solidMap(10, 10, red)
+	bounds := onmap.Bounds{SW: onmap.Coord{-10, -10}, NE: onmap.Coord{10, 10}}
+	onmap.DrawGeoImage(m, onmap.Mercator, tile, bounds)
+
+	min := onmap.Mercator.Convert(onmap.Coord{10, -10}, 360, 360)
+	max := onmap.Mercator.Convert(onmap.Coord{-10, 10}, 360, 360)
+	r := image.Rectangle{min, max}
+	for y := r.Min.Y - 2; y < r.Max.Y+2; y++ {
+		for x := r.Min.X - 2; x < r.Max.X+2; x++ {
+			expected := color.Color(color.White)
+			if (image.Point{x, y}).In(r) {
+				expected = red
+			}
+			if c := m.At(x, y); !sameColor(c, expected) {
+				t.Fatalf("pixel (%d, %d): expected %v, got %v", x, y, expected, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// PinStyle defines the appearance of pins returned by PinParts.
+type PinStyle struct {
+	// Pin is the pin image. If nil, the embedded pin is used.
+	Pin image.Image
+
+	// LightAzimuthDeg is the direction the light comes from in degrees
+	// clockwise from north (the top of the map). Shadows fall onto
+	// the ground behind the pin and lean away from the light:
+	// light from the west (270) casts shadows to the right,
+	// light from the east (90) to the left.
+	LightAzimuthDeg float64
+
+	// ShadowOffset moves the shadow relative to the pin by the given
+	// number of pixels. Since pin parts without anchors are positioned
+	// by their bottom edge, PinParts can't move shadows below the point
+	// and ignores positive Y offsets: use PinPartsAnchored and draw
+	// parts with the returned anchors to move shadows in any direction.
+	ShadowOffset image.Point
+
+	// NoShadow, if true, disables the shadow for a flat look.
+	NoShadow bool
+}
+
+// Shadow generator parameters.
+const (
+	shadowLength  = 0.8  // relative to pin height
+	shadowFlat    = 0.5  // vertical foreshortening of the ground
+	shadowOpacity = 0.35 // maximum alpha
+	shadowBlur    = 2    // blur radius in pixels
+)
+
+// PinParts returns pin parts for the style: a shadow generated
+// from the pin silhouette according to the light direction,
+// unless disabled, and the pin itself.
+func PinParts(style PinStyle) []image.Image {
+	pin := style.Pin
+	if pin == nil {
+		pin = DefaultPin()[1]
+	}
+	if style.NoShadow {
+		return []image.Image{pin}
+	}
+	shadow := pinShadow(pin, style.LightAzimuthDeg)
+	if style.ShadowOffset != (image.Point{}) {
+		shadow = offsetPart(shadow, style.ShadowOffset)
+	}
+	return []image.Image{shadow, pin}
+}
+
+// PinPartsAnchored is like PinParts, but also returns anchors to draw
+// the pin parts with, such as in Options.Anchors or Pin.Anchors,
+// which position the shadow moved by ShadowOffset in any direction.
+func PinPartsAnchored(style PinStyle) ([]image.Image, []Anchor) {
+	offset := style.ShadowOffset
+	style.ShadowOffset = image.Point{}
+	parts := PinParts(style)
+	anchors := AnchorsAt(parts, 0.5, 1)
+	if len(parts) > 1 {
+		anchors[0].Offset = offset
+	}
+	return parts, anchors
+}
+
+// offsetPart returns the pin part padded with transparent pixels,
+// so that it's drawn moved by the offset (with non-positive Y).
+func offsetPart(part image.Image, offset image.Point) image.Image {
+	if offset.Y > 0 {
+		offset.Y = 0
+	}
+	b := part.Bounds()
+	dx := offset.X
+	if dx < 0 {
+		dx = -dx
+	}
+	m := image.NewRGBA(image.Rect(0, 0, b.Dx()+2*dx, b.Dy()-offset.Y))
+	draw.Draw(m, b.Sub(b.Min).Add(image.Point{dx + offset.X, 0}), part, b.Min, draw.Src)
+	return m
+}
+
+// pinShadow generates the shadow of the pin cast by light coming
+// from the given azimuth. The tip of the shadow is at its bottom center,
+// like the tip of the pin, so they can be drawn at the same point.
+func pinShadow(pin image.Image, azimuth float64) image.Image {
+	b := pin.Bounds()
+	// Direction of the shadow on the ground, away from the light.
+	d := (azimuth + 180) * math.Pi / 180
+	lean := shadowLength * math.Sin(d)
+	rise := shadowLength * shadowFlat * (1 + math.Abs(math.Cos(d))) / 2
+
+	tipX := float64(b.Dx()) / 2
+	tipY := float64(b.Dy())
+	transform := func(x, y float64) (float64, float64) {
+		h := tipY - y
+		return x - tipX + h*lean, -h * rise
+	}
+
+	// Compute shadow dimensions, keeping the tip at the bottom center.
+	halfw := 0.0
+	for _, p := range [][2]float64{{0, 0}, {float64(b.Dx()), 0}, {0, tipY}, {float64(b.Dx()), tipY}} {
+		x, _ := transform(p[0], p[1])
+		halfw = math.Max(halfw, math.Abs(x))
+	}
+	// Leave room for blur on the sides and the top.
+	w := (int(math.Ceil(halfw)) + shadowBlur) * 2
+	h := int(math.Ceil(tipY*rise)) + shadowBlur
+	shadow := image.NewAlpha(image.Rect(0, 0, w, h))
+
+	// Splat subsamples of the pin silhouette onto the shadow.
+	const sub = 2
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			_, _, _, a := pin.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			if a == 0 {
+				continue
+			}
+			sa := uint8(float64(a>>8) * shadowOpacity)
+			for sy := 0; sy < sub; sy++ {
+				for sx := 0; sx < sub; sx++ {
+					fx, fy := transform(float64(x)+(float64(sx)+0.5)/sub, float64(y)+(float64(sy)+0.5)/sub)
+					px := int(math.Floor(fx + float64(w)/2))
+					py := int(math.Floor(fy + float64(h)))
+					if !(image.Point{px, py}).In(shadow.Rect) {
+						continue
+					}
+					if shadow.AlphaAt(px, py).A < sa {
+						shadow.SetAlpha(px, py, color.Alpha{sa})
+					}
+				}
+			}
+		}
+	}
+	blurAlpha(shadow, shadowBlur)
+	blurAlpha(shadow, shadowBlur)
+
+	// Use black shadow with the computed alpha.
+	m := image.NewRGBA(shadow.Rect)
+	for i, a := range shadow.Pix {
+		m.Pix[i*4+3] = a
+	}
+	return m
+}
+
+// blurAlpha applies box blur with the given radius to the alpha image.
+func blurAlpha(m *image.Alpha, radius int) {
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	tmp := make([]uint8, len(m.Pix))
+	blur := func(dst, src []uint8, n, count, stride, step int) {
+		for j := 0; j < count; j++ {
+			for i := 0; i < n; i++ {
+				sum, k := 0, 0
+				for d := -radius; d <= radius; d++ {
+					if i+d >= 0 && i+d < n {
+						sum += int(src[j*stride+(i+d)*step])
+					}
+					k++
+				}
+				dst[j*stride+i*step] = uint8(sum / k)
+			}
+		}
+	}
+	blur(tmp, m.Pix, w, h, m.Stride, 1)
+	blur(m.Pix, tmp, h, w, 1, m.Stride)
+}
+
+// GeneratePin draws a teardrop pin of the given size in pixels
+// with the body and outline colors, and its soft elliptical shadow.
+// The pin image is size×size pixels with the tip at its bottom center.
+// The result can be used as pin parts: []image.Image{shadow, pin}.
+func GeneratePin(size int, body, outline color.Color) (pin, shadow image.Image) {
+	s := float64(size)
+	r := image.Rect(0, 0, size, size)
+	head := s * 0.32
+	hx, hy := s/2, head+s*0.04
+	outlineWidth := math.Max(1, s/24)
+	teardrop := func(x, y float64) float64 {
+		return unevenCapsule(x, y, hx, s-0.5, hx, hy, 0.5, head)
+	}
+	m := image.NewRGBA(r)
+	fillShape(m, &shapeMask{r, teardrop}, outline)
+	fillShape(m, &shapeMask{r, func(x, y float64) float64 {
+		return teardrop(x, y) + outlineWidth
+	}}, body)
+	fillCircle(m, hx, hy, head*0.35, outline)
+
+	// Shadow is an ellipse lying on the ground around the tip,
+	// fading out towards its edge.
+	sw, sh := size, int(math.Ceil(s/4))
+	sm := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	cx, cy := float64(sw)/2, float64(sh)/2
+	rx, ry := cx, cy
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			dx := (float64(x) + 0.5 - cx) / rx
+			dy := (float64(y) + 0.5 - cy) / ry
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d >= 1 {
+				continue
+			}
+			sm.Pix[y*sm.Stride+x*4+3] = uint8(0xff * shadowOpacity * (1 - d*d))
+		}
+	}
+	return m, sm
+}
+
+// unevenCapsule returns the signed distance from (x, y) to the shape
+// formed by circles at (ax, ay) and (bx, by) with radii ra and rb
+// and their common tangent lines.
+func unevenCapsule(x, y, ax, ay, bx, by, ra, rb float64) float64 {
+	px, py := x-ax, y-ay
+	dx, dy := bx-ax, by-ay
+	h := dx*dx + dy*dy
+	qx := math.Abs(px*dy-py*dx) / h
+	qy := (px*dx + py*dy) / h
+	b := ra - rb
+	cx, cy := math.Sqrt(h-b*b), b
+	k := cx*qy - cy*qx
+	n := qx*qx + qy*qy
+	switch {
+	case k < 0:
+		return math.Sqrt(h*n) - ra
+	case k > cx:
+		return math.Sqrt(h*(n+1-2*qy)) - rb
+	}
+	return cx*qx + cy*qy - ra
+}
@@ -0,0 +1,129 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+// alphaCentroidX returns the x coordinate of the centroid of the image
+// alpha relative to the horizontal center of the image.
+func alphaCentroidX(m image.Image) float64 {
+	b := m.Bounds()
+	var sum, total float64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			_, _, _, a := m.At(x, y).RGBA()
+			sum += float64(x) * float64(a)
+			total += float64(a)
+		}
+	}
+	return sum/total - float64(b.Min.X+b.Max.X)/2
+}
+
+func TestPinPartsLightAzimuth(t *testing.T) {
+	west := onmap.PinParts(onmap.PinStyle{LightAzimuthDeg: 270})
+	if len(west) != 2 {
+		t.Fatalf("expected 2 pin parts, got %d", len(west))
+	}
+	if x := alphaCentroidX(west[0]); x <= 0 {
+		t.Errorf("light from the west: expected shadow to the right, got centroid offset %f", x)
+	}
+	east := onmap.PinParts(onmap.PinStyle{LightAzimuthDeg: 90})
+	if x := alphaCentroidX(east[0]); x >= 0 {
+		t.Errorf("light from the east: expected shadow to the left, got centroid offset %f", x)
+	}
+}
+
+func TestPinPartsShadowOffset(t *testing.T) {
+	plain := onmap.PinParts(onmap.PinStyle{})
+	moved := onmap.PinParts(onmap.PinStyle{ShadowOffset: image.Point{10, -5}})
+	if d := alphaCentroidX(moved[0]) - alphaCentroidX(plain[0]); d < 9 || d > 11 {
+		t.Errorf("expected shadow moved 10 pixels to the right, got %f", d)
+	}
+	if h := moved[0].Bounds().Dy() - plain[0].Bounds().Dy(); h != 5 {
+		t.Errorf("expected shadow moved 5 pixels up, got %d", h)
+	}
+
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{0, 0}}
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+	m := onmap.Render(worldMap, moved, coords, nil)
+	if countNotColor(m.(*image.RGBA).SubImage(image.Rect(p.X+20, p.Y-40, p.X+60, p.Y)), color.White) == 0 {
+		t.Errorf("expected shadow to the right of the pin")
+	}
+}
+
+func TestPinPartsAnchored(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{0, 0}}
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+	below := image.Rect(p.X-30, p.Y+2, p.X+30, p.Y+12)
+
+	parts, anchors := onmap.PinPartsAnchored(onmap.PinStyle{})
+	m := onmap.Render(worldMap, parts, coords, &onmap.Options{Anchors: anchors})
+	if !sameImage(m, onmap.Render(worldMap, parts, coords, nil)) {
+		t.Errorf("expected anchors without offset to match the default position")
+	}
+	if n := countNotColor(m.(*image.RGBA).SubImage(below), color.White); n != 0 {
+		t.Errorf("expected no shadow below the point, got %d pixels", n)
+	}
+
+	// Shadow moved below the point.
+	parts, anchors = onmap.PinPartsAnchored(onmap.PinStyle{ShadowOffset: image.Point{0, 10}})
+	m = onmap.Render(worldMap, parts, coords, &onmap.Options{Anchors: anchors})
+	if countNotColor(m.(*image.RGBA).SubImage(below), color.White) == 0 {
+		t.Errorf("expected shadow below the point")
+	}
+
+	// Shadow moved up and to the right, as with PinParts.
+	style := onmap.PinStyle{ShadowOffset: image.Point{10, -5}}
+	parts, anchors = onmap.PinPartsAnchored(style)
+	m = onmap.Render(worldMap, parts, coords, &onmap.Options{Anchors: anchors})
+	if !sameImage(m, onmap.Render(worldMap, onmap.PinParts(style), coords, nil)) {
+		t.Errorf("expected anchored shadow to match the padded one")
+	}
+}
+
+func TestPinPartsNoShadow(t *testing.T) {
+	parts := onmap.PinParts(onmap.PinStyle{NoShadow: true})
+	if len(parts) != 1 {
+		t.Fatalf("expected 1 pin part, got %d", len(parts))
+	}
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{0, 0}}
+	with := onmap.Render(worldMap, onmap.PinParts(onmap.PinStyle{}), coords, nil)
+	without := onmap.Render(worldMap, parts, coords, nil)
+	if n1, n2 := countNotColor(with, color.White), countNotColor(without, color.White); n2 >= n1 {
+		t.Errorf("expected fewer drawn pixels without shadow, got %d and %d", n1, n2)
+	}
+}
+
+func TestGeneratePin(t *testing.T) {
+	blue := color.RGBA{0x20, 0x40, 0xe0, 0xff}
+	pin, shadow := onmap.GeneratePin(48, blue, color.Black)
+	if s := pin.Bounds().Size(); s != (image.Point{48, 48}) {
+		t.Fatalf("expected 48x48 pin, got %v", s)
+	}
+	b := pin.Bounds()
+	// The anchor is at the bottom center: the tip must be drawn there.
+	if _, _, _, a := pin.At(b.Min.X+b.Dx()/2, b.Max.Y-1).RGBA(); a == 0 {
+		t.Errorf("expected tip pixel at the bottom center")
+	}
+	if _, _, _, a := pin.At(b.Min.X, b.Max.Y-1).RGBA(); a != 0 {
+		t.Errorf("expected transparent bottom corner, got alpha %d", a)
+	}
+	// Body color inside the head, outside of the center hole.
+	if c := color.RGBAModel.Convert(pin.At(b.Dx()/2, b.Dy()/8)).(color.RGBA); c != blue {
+		t.Errorf("expected body color %v, got %v", blue, c)
+	}
+	if _, _, _, a := shadow.At(shadow.Bounds().Dx()/2, shadow.Bounds().Dy()/2).RGBA(); a == 0 {
+		t.Errorf("expected shadow at the center")
+	}
+	m := onmap.MapPinsRGBA(onmap.Mercator, onmap.DefaultMap(), []image.Image{shadow, pin}, []onmap.Coord{{51.5, -0.12}}, onmap.StandardCrop)
+	if err := writePng("test-generated-pin.png", m); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,77 @@
+package onmap
+
+import (
+	"image"
+	"sort"
+)
+
+// ProjectedSet is a set of coordinates converted to points on the map
+// of the given size, which can be rendered multiple times, for example,
+// with different crops, without converting and sorting coordinates
+// each time.
+type ProjectedSet struct {
+	// Points are the points on the map.
+	Points []image.Point
+
+	// MapWidth and MapHeight are the dimensions of the map
+	// the coordinates were projected on.
+	MapWidth, MapHeight int
+
+	proj   Projection
+	sorted []image.Point // points in the order of drawing
+}
+
+// Project converts coordinates to points on the map of the given size
+// in the projection.
+func Project(proj Projection, mapWidth, mapHeight int, coords []Coord) ProjectedSet {
+	points := project(proj, coords, mapWidth, mapHeight)
+	return ProjectedSet{
+		Points:    points,
+		MapWidth:  mapWidth,
+		MapHeight: mapHeight,
+		proj:      proj,
+		sorted:    sortPoints(points),
+	}
+}
+
+// sortPoints returns a copy of points sorted in the order of drawing.
+func sortPoints(points []image.Point) []image.Point {
+	sorted := make([]image.Point, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+	return sorted
+}
+
+// MapPins is like MapPinsProjection, but marks the projected points.
+// The world map must have the size the set was projected for.
+// If crop is nil, doesn't crop the image.
+func (s ProjectedSet) MapPins(worldMap image.Image, pinParts []image.Image, crop *CropOption) image.Image {
+	rect := image.Rect(0, 0, s.MapWidth, s.MapHeight)
+	l := layout{proj: s.proj, mapRect: rect, canvas: rect}
+	points, sorted := s.Points, s.sorted
+	if crop != nil && crop.WrapAntimeridian && crop.FixedBounds == nil {
+		// Roll the map as layoutPoints does, which changes the order.
+		if l.shift = wrapShift(points, rect); l.shift != 0 {
+			points = make([]image.Point, len(s.Points))
+			for i, p := range s.Points {
+				points[i] = image.Point{mod(p.X+l.shift, s.MapWidth), p.Y}
+			}
+			sorted = sortPoints(points)
+		}
+	}
+	r := l.cropRect(points, crop)
+	m := image.NewRGBA(r)
+	l.drawMap(m, worldMap, nil, sceneAll)
+	// Points are already sorted, so draw them in this order.
+	opt := &Options{Projection: s.proj, Crop: crop, InputOrder: true}
+	markers, _ := makeMarkers(sorted, r, opt, func(i int, pt image.Point) marker {
+		return marker{pt: pt, parts: pinParts}
+	})
+	drawMarkers(m, markers, opt)
+	return m
+}
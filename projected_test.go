@@ -0,0 +1,79 @@
+package onmap_test
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestProjectedSet(t *testing.T) {
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{42.441286, 19.262892},   // Podgorica
+		{41.9097306, 12.2558141}, // Rome
+	}
+	worldMap := onmap.DefaultMap()
+	b := worldMap.Bounds()
+	set := onmap.Project(onmap.Mercator, b.Dx(), b.Dy(), coords)
+	if len(set.Points) != len(coords) {
+		t.Fatalf("expected %d points, got %d", len(coords), len(set.Points))
+	}
+	for i, c := range coords {
+		if p := onmap.Mercator.Convert(c, b.Dx(), b.Dy()); set.Points[i] != p {
+			t.Errorf("point %d: expected %v, got %v", i, p, set.Points[i])
+		}
+	}
+	for _, crop := range []*onmap.CropOption{nil, onmap.StandardCrop, {Bound: 10}} {
+		expected := onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, crop)
+		m := set.MapPins(worldMap, onmap.DefaultPin(), crop)
+		if !sameImage(expected, m) {
+			t.Errorf("crop %v: projected set rendering differs from MapPinsProjection", crop)
+		}
+	}
+
+	// Points on both sides of the date line roll the map.
+	pacific := []onmap.Coord{{-17, 178}, {-14, -171}, {-13, -172}}
+	set = onmap.Project(onmap.Mercator, b.Dx(), b.Dy(), pacific)
+	crop := &onmap.CropOption{Bound: 20, WrapAntimeridian: true}
+	expected := onmap.MapPinsProjection(onmap.Mercator, worldMap, onmap.DefaultPin(), pacific, crop)
+	m := set.MapPins(worldMap, onmap.DefaultPin(), crop)
+	if expected.Bounds() != m.Bounds() {
+		t.Errorf("wrap: expected bounds %v, got %v", expected.Bounds(), m.Bounds())
+	} else if !sameImage(expected, m) {
+		t.Errorf("wrap: projected set rendering differs from MapPinsProjection")
+	}
+}
+
+func benchmarkCrops() []*onmap.CropOption {
+	return []*onmap.CropOption{
+		onmap.StandardCrop,
+		{Bound: 10},
+		{Bound: 50, PreserveRatio: true},
+		{Bound: 100, MinWidth: 400, MinHeight: 300},
+	}
+}
+
+func BenchmarkProjectedSet(b *testing.B) {
+	worldMap := onmap.DefaultMap()
+	mb := worldMap.Bounds()
+	coords := randomCoords(20000, onmap.Bounds{SW: onmap.Coord{35, -10}, NE: onmap.Coord{60, 30}})
+	crops := benchmarkCrops()
+	// Small pins, so that drawing doesn't dominate.
+	dot := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(dot, dot.Bounds(), image.Black, image.Point{}, draw.Src)
+	pinParts := []image.Image{dot}
+	b.Run("MapPins", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			onmap.MapPinsProjection(onmap.Mercator, worldMap, pinParts, coords, crops[i%len(crops)])
+		}
+	})
+	b.Run("ProjectedSet", func(b *testing.B) {
+		set := onmap.Project(onmap.Mercator, mb.Dx(), mb.Dy(), coords)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			set.MapPins(worldMap, pinParts, crops[i%len(crops)])
+		}
+	})
+}
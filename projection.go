@@ -0,0 +1,190 @@
+package onmap
+
+import (
+	"image"
+	"math"
+	"sync"
+)
+
+// Equirectangular provides the equirectangular (plate carrée) projection,
+// which maps longitude linearly across the map width and latitude linearly
+// across the map height. World maps used with it must be in the same
+// projection, usually with the 2:1 aspect ratio.
+var Equirectangular = equirectangularProjection(0)
+
+type equirectangularProjection int
+
+func (p equirectangularProjection) Convert(c Coord, mapWidth, mapHeight int) image.Point {
+	mw := float64(mapWidth)
+	mh := float64(mapHeight)
+	fx := (c.Long + 180) * (mw / 360)
+	fy := (90 - c.Lat) * (mh / 180)
+	return image.Point{int(math.Round(fx)), int(math.Round(fy))}
+}
+
+func (p equirectangularProjection) Unconvert(pt image.Point, mapWidth, mapHeight int) Coord {
+	return Coord{
+		Lat:  90 - float64(pt.Y)*180/float64(mapHeight),
+		Long: float64(pt.X)*360/float64(mapWidth) - 180,
+	}
+}
+
+// WebMercator provides the spherical Web Mercator projection (EPSG:3857)
+// used by tile servers such as OpenStreetMap and Google Maps.
+// The world map must cover the whole projected square, with latitudes
+// from -85.0511° to 85.0511°, stretched to the map width and height.
+// Latitudes outside of this range are clamped.
+var WebMercator = webMercatorProjection(0)
+
+// webMercatorMaxLat is the latitude at which Web Mercator map is square.
+const webMercatorMaxLat = 85.05112878
+
+type webMercatorProjection int
+
+func (p webMercatorProjection) Convert(c Coord, mapWidth, mapHeight int) image.Point {
+	mw := float64(mapWidth)
+	mh := float64(mapHeight)
+	lat := math.Max(-webMercatorMaxLat, math.Min(webMercatorMaxLat, c.Lat))
+	fx := (c.Long + 180) * (mw / 360)
+	fy := (1 - math.Asinh(math.Tan(lat*math.Pi/180))/math.Pi) * (mh / 2)
+	return image.Point{int(math.Round(fx)), int(math.Round(fy))}
+}
+
+func (p webMercatorProjection) Unconvert(pt image.Point, mapWidth, mapHeight int) Coord {
+	n := (1 - 2*float64(pt.Y)/float64(mapHeight)) * math.Pi
+	return Coord{
+		Lat:  math.Atan(math.Sinh(n)) * 180 / math.Pi,
+		Long: float64(pt.X)*360/float64(mapWidth) - 180,
+	}
+}
+
+// Extent returns the projection for maps covering only the region
+// between the north-west and south-east corners in the given projection,
+// such as regional maps. The region is mapped onto the whole map, and
+// coordinates outside of the region are converted to points outside
+// of the map.
+func Extent(p Projection, nw, se Coord) Projection {
+	return &extentProjection{
+		p:  p,
+		nw: p.Convert(nw, extentSize, extentSize),
+		se: p.Convert(se, extentSize, extentSize),
+	}
+}
+
+// extentSize is the size of the world map used to calculate
+// positions within extents with enough precision.
+const extentSize = 1 << 20
+
+type extentProjection struct {
+	p      Projection
+	nw, se image.Point
+}
+
+func (e *extentProjection) hidden(c Coord) bool {
+	return isHidden(e.p, c)
+}
+
+// hider is implemented by projections that can't show some coordinates,
+// such as those on the far side of the globe.
+type hider interface {
+	hidden(c Coord) bool
+}
+
+// isHidden reports whether the coordinate can't be shown in the projection.
+func isHidden(p Projection, c Coord) bool {
+	h, ok := p.(hider)
+	return ok && h.hidden(c)
+}
+
+func (e *extentProjection) Convert(c Coord, mapWidth, mapHeight int) image.Point {
+	pt := e.p.Convert(c, extentSize, extentSize)
+	fx := float64(pt.X-e.nw.X) / float64(e.se.X-e.nw.X) * float64(mapWidth)
+	fy := float64(pt.Y-e.nw.Y) / float64(e.se.Y-e.nw.Y) * float64(mapHeight)
+	return image.Point{int(math.Round(fx)), int(math.Round(fy))}
+}
+
+// Orthographic returns the orthographic projection centered on the given
+// coordinate, which shows the visible hemisphere of the globe as seen
+// from space. The globe is a disc as large as fits into the map centered
+// on it. Coordinates on the far side of the globe are converted
+// to a point outside of the map, so their pins are not drawn,
+// and they don't affect crops.
+func Orthographic(center Coord) Projection {
+	return &orthographicProjection{
+		lat:  center.Lat * math.Pi / 180,
+		long: center.Long * math.Pi / 180,
+	}
+}
+
+type orthographicProjection struct {
+	lat, long float64 // in radians
+}
+
+// disc returns the center and radius of the globe on the map.
+func (o *orthographicProjection) disc(mapWidth, mapHeight int) (cx, cy, r float64) {
+	return float64(mapWidth) / 2, float64(mapHeight) / 2, math.Min(float64(mapWidth), float64(mapHeight)) / 2
+}
+
+// hidden reports whether the coordinate is on the far side of the globe.
+func (o *orthographicProjection) hidden(c Coord) bool {
+	lat := c.Lat * math.Pi / 180
+	dl := c.Long*math.Pi/180 - o.long
+	return math.Sin(o.lat)*math.Sin(lat)+math.Cos(o.lat)*math.Cos(lat)*math.Cos(dl) < 0
+}
+
+func (o *orthographicProjection) Convert(c Coord, mapWidth, mapHeight int) image.Point {
+	if o.hidden(c) {
+		return image.Point{-mapWidth, -mapHeight}
+	}
+	lat := c.Lat * math.Pi / 180
+	dl := c.Long*math.Pi/180 - o.long
+	cx, cy, r := o.disc(mapWidth, mapHeight)
+	x := r * math.Cos(lat) * math.Sin(dl)
+	y := r * (math.Cos(o.lat)*math.Sin(lat) - math.Sin(o.lat)*math.Cos(lat)*math.Cos(dl))
+	return image.Point{int(math.Round(cx + x)), int(math.Round(cy - y))}
+}
+
+func (o *orthographicProjection) Unconvert(pt image.Point, mapWidth, mapHeight int) Coord {
+	cx, cy, r := o.disc(mapWidth, mapHeight)
+	x, y := float64(pt.X)-cx, cy-float64(pt.Y)
+	rho := math.Hypot(x, y)
+	if rho == 0 {
+		return Coord{o.lat * 180 / math.Pi, o.long * 180 / math.Pi}
+	}
+	// Points outside of the globe are moved to its edge.
+	c := math.Asin(math.Min(1, rho/r))
+	lat := math.Asin(math.Cos(c)*math.Sin(o.lat) + y*math.Sin(c)*math.Cos(o.lat)/rho)
+	long := o.long + math.Atan2(x*math.Sin(c), rho*math.Cos(c)*math.Cos(o.lat)-y*math.Sin(c)*math.Sin(o.lat))
+	return Coord{
+		Lat:  lat * 180 / math.Pi,
+		Long: math.Remainder(long*180/math.Pi, 360),
+	}
+}
+
+var (
+	projectionsMu sync.RWMutex
+	projections   = map[string]Projection{
+		"mercator":        Mercator,
+		"webmercator":     WebMercator,
+		"equirectangular": Equirectangular,
+	}
+)
+
+// RegisterProjection registers the projection under the given name
+// for ProjectionByName, replacing the projection previously registered
+// under this name, if any. Built-in projections are registered as
+// "mercator", "webmercator", and "equirectangular".
+func RegisterProjection(name string, p Projection) {
+	projectionsMu.Lock()
+	defer projectionsMu.Unlock()
+	projections[name] = p
+}
+
+// ProjectionByName returns the projection registered under the given
+// name and true, or nil and false if there is no such projection.
+func ProjectionByName(name string) (Projection, bool) {
+	projectionsMu.RLock()
+	defer projectionsMu.RUnlock()
+	p, ok := projections[name]
+	return p, ok
+}
@@ -0,0 +1,241 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMercatorPoles(t *testing.T) {
+	sizes := []image.Point{
+		onmap.DefaultMap().Bounds().Size(),
+		image.Pt(1000, 1000),
+	}
+	for _, size := range sizes {
+		for _, c := range []onmap.Coord{{90, 0}, {-90, 0}, {89.99, 0}, {-89.99, 0}} {
+			p := onmap.Mercator.Convert(c, size.X, size.Y)
+			if p.Y < 0 || p.Y > size.Y {
+				t.Errorf("%v on %v map: Y %d is outside of [0, %d]", c, size, p.Y, size.Y)
+			}
+		}
+	}
+}
+
+func TestEquirectangular(t *testing.T) {
+	tests := []struct {
+		c    onmap.Coord
+		want image.Point
+	}{
+		{onmap.Coord{0, 0}, image.Pt(1000, 500)},
+		{onmap.Coord{90, -180}, image.Pt(0, 0)},
+		{onmap.Coord{-90, 180}, image.Pt(2000, 1000)},
+		{onmap.Coord{-45, 90}, image.Pt(1500, 750)},
+	}
+	for _, tt := range tests {
+		if p := onmap.Equirectangular.Convert(tt.c, 2000, 1000); p != tt.want {
+			t.Errorf("%v: expected %v, got %v", tt.c, tt.want, p)
+		}
+	}
+
+	worldMap := solidMap(2000, 1000, color.White)
+	m := onmap.MapPinsProjection(onmap.Equirectangular, worldMap, onmap.DefaultPin(), []onmap.Coord{{0, 0}}, nil)
+	if sameColor(m.At(1000, 499), color.White) {
+		t.Errorf("expected pin at the center of the map")
+	}
+	if !sameColor(m.At(1000, 501), color.White) {
+		t.Errorf("expected no pin below the center of the map")
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	// Pixel positions at zoom level 10 of 256x256 tiles.
+	const size = 256 << 10
+	tests := []struct {
+		name string
+		c    onmap.Coord
+		tile image.Point
+		want image.Point
+	}{
+		{"London", onmap.Coord{51.5074, -0.1278}, image.Pt(511, 340), image.Pt(130979, 87170)},
+		{"Berlin", onmap.Coord{52.52, 13.405}, image.Pt(550, 335), image.Pt(140833, 85971)},
+		{"Tokyo", onmap.Coord{35.6895, 139.6917}, image.Pt(909, 403), image.Pt(232792, 103219)},
+		{"Sydney", onmap.Coord{-33.8688, 151.2093}, image.Pt(942, 614), image.Pt(241179, 157311)},
+	}
+	for _, tt := range tests {
+		p := onmap.WebMercator.Convert(tt.c, size, size)
+		if p.Div(256) != tt.tile {
+			t.Errorf("%s: expected tile %v, got %v", tt.name, tt.tile, p.Div(256))
+		}
+		if d := p.Sub(tt.want); abs(d.X) > 1 || abs(d.Y) > 1 {
+			t.Errorf("%s: expected pixel %v, got %v", tt.name, tt.want, p)
+		}
+	}
+
+	// Latitudes beyond the valid range are clamped.
+	if p := onmap.WebMercator.Convert(onmap.Coord{89, 0}, 512, 512); p != image.Pt(256, 0) {
+		t.Errorf("expected north pole clamped to %v, got %v", image.Pt(256, 0), p)
+	}
+	if p := onmap.WebMercator.Convert(onmap.Coord{-89, 0}, 512, 512); p != image.Pt(256, 512) {
+		t.Errorf("expected south pole clamped to %v, got %v", image.Pt(256, 512), p)
+	}
+}
+
+func TestUnconvert(t *testing.T) {
+	coords := []onmap.Coord{
+		{0, 0},
+		{42.1, 19.1},             // Bar
+		{55.755833, 37.617222},   // Moscow
+		{-31.952222, 115.858889}, // Perth
+		{37.7775, -122.416389},   // San Francisco
+	}
+	projs := map[string]onmap.InverseProjection{
+		"Mercator":        onmap.Mercator,
+		"Equirectangular": onmap.Equirectangular,
+		"WebMercator":     onmap.WebMercator,
+	}
+	const w, h = 1920, 1629
+	// Rounding to pixels loses up to half a pixel, which
+	// is at most this many degrees in these projections.
+	tolerance := 360.0 / w
+	for name, proj := range projs {
+		for _, c := range coords {
+			u := proj.Unconvert(proj.Convert(c, w, h), w, h)
+			if math.Abs(u.Lat-c.Lat) > tolerance || math.Abs(u.Long-c.Long) > tolerance {
+				t.Errorf("%s: %v converted back to %v", name, c, u)
+			}
+		}
+	}
+}
+
+func TestExtent(t *testing.T) {
+	nw := onmap.Coord{75, -30}
+	se := onmap.Coord{30, 60}
+	for name, proj := range map[string]onmap.Projection{
+		"Mercator":        onmap.Mercator,
+		"Equirectangular": onmap.Equirectangular,
+	} {
+		ext := onmap.Extent(proj, nw, se)
+		if p := ext.Convert(nw, 900, 600); p != image.Pt(0, 0) {
+			t.Errorf("%s: expected north-west corner at (0, 0), got %v", name, p)
+		}
+		if p := ext.Convert(se, 900, 600); p != image.Pt(900, 600) {
+			t.Errorf("%s: expected south-east corner at (900, 600), got %v", name, p)
+		}
+		if p := ext.Convert(onmap.Coord{30, 15}, 900, 600); p != image.Pt(450, 600) {
+			t.Errorf("%s: expected middle of the bottom edge at (450, 600), got %v", name, p)
+		}
+		if p := ext.Convert(onmap.Coord{-31.952222, 115.858889}, 900, 600); p.In(image.Rect(0, 0, 900, 600)) {
+			t.Errorf("%s: expected point outside of the region to be outside of the map, got %v", name, p)
+		}
+	}
+
+	// Equirectangular extent is linear.
+	ext := onmap.Extent(onmap.Equirectangular, nw, se)
+	if p := ext.Convert(onmap.Coord{52.5, 15}, 900, 600); p != image.Pt(450, 300) {
+		t.Errorf("expected center at (450, 300), got %v", p)
+	}
+}
+
+func TestProjectionByName(t *testing.T) {
+	if p, ok := onmap.ProjectionByName("mercator"); !ok || p != onmap.Mercator {
+		t.Errorf("expected Mercator, got %v, %v", p, ok)
+	}
+
+	ext := onmap.Extent(onmap.Equirectangular, onmap.Coord{75, -30}, onmap.Coord{30, 60})
+	onmap.RegisterProjection("test-europe", ext)
+	if p, ok := onmap.ProjectionByName("test-europe"); !ok || p != ext {
+		t.Errorf("expected registered projection, got %v, %v", p, ok)
+	}
+
+	if p, ok := onmap.ProjectionByName("no-such-projection"); ok || p != nil {
+		t.Errorf("expected not found, got %v, %v", p, ok)
+	}
+}
+
+func TestOrthographic(t *testing.T) {
+	center := onmap.Coord{48.9, 2.4}
+	proj := onmap.Orthographic(center)
+	bounds := image.Rect(0, 0, 800, 600)
+	if p := proj.Convert(center, 800, 600); p != image.Pt(400, 300) {
+		t.Errorf("expected center at (400, 300), got %v", p)
+	}
+	antipode := onmap.Coord{-center.Lat, center.Long - 180}
+	if p := proj.Convert(antipode, 800, 600); p.In(bounds) {
+		t.Errorf("expected antipode off the map, got %v", p)
+	}
+	// The north pole is visible above the center, on the globe.
+	if p := proj.Convert(onmap.Coord{90, 0}, 800, 600); p.X != 400 || p.Y >= 300 || p.Y < 0 {
+		t.Errorf("expected north pole above the center, got %v", p)
+	}
+	// A point 90° away is on the edge of the globe.
+	if p := proj.Convert(onmap.Coord{0, center.Long + 90}, 800, 600); math.Hypot(float64(p.X-400), float64(p.Y-300)) > 301 {
+		t.Errorf("expected point within the globe, got %v", p)
+	}
+
+	inv := proj.(onmap.InverseProjection)
+	for _, c := range []onmap.Coord{center, {51.5, -0.1}, {30, 40}, {60, -30}} {
+		got := inv.Unconvert(proj.Convert(c, 800, 600), 800, 600)
+		if math.Abs(got.Lat-c.Lat) > 0.5 || math.Abs(got.Long-c.Long) > 0.5 {
+			t.Errorf("expected %v, got %v", c, got)
+		}
+	}
+}
+
+func TestOrthographicCrop(t *testing.T) {
+	center := onmap.Coord{48.9, 2.4}
+	proj := onmap.Orthographic(center)
+	worldMap := solidMap(800, 600, color.White)
+	near := []onmap.Coord{center, {51.5, -0.1}}
+	antipode := onmap.Coord{-center.Lat, center.Long - 180}
+	opt := &onmap.Options{Projection: proj, Crop: onmap.StandardCrop}
+
+	// Pins on the far side of the globe don't stretch the crop.
+	want := onmap.Render(worldMap, onmap.DefaultPin(), near, opt)
+	got := onmap.Render(worldMap, onmap.DefaultPin(), append(near, antipode), opt)
+	if got.Bounds() != want.Bounds() {
+		t.Errorf("expected crop %v, got %v", want.Bounds(), got.Bounds())
+	}
+	r := onmap.CropRect(proj, 800, 600, append(near, antipode), onmap.StandardCrop)
+	if r != want.Bounds() {
+		t.Errorf("expected CropRect %v, got %v", want.Bounds(), r)
+	}
+}
+
+func TestRenderMapBounds(t *testing.T) {
+	// Mercator map of the eastern hemisphere.
+	worldMap := solidMap(180, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	pin := []image.Image{solidMap(10, 10, red)}
+	opt := &onmap.Options{MapBounds: &onmap.Bounds{
+		SW: onmap.Coord{-85.05113, 0},
+		NE: onmap.Coord{85.05113, 180},
+	}}
+	m := onmap.Render(worldMap, pin, []onmap.Coord{{0, 90}}, opt)
+	if m.Bounds() != worldMap.Bounds() {
+		t.Fatalf("expected image bounds %v, got %v", worldMap.Bounds(), m.Bounds())
+	}
+	if c := m.At(90, 175); !sameColor(c, red) {
+		t.Errorf("expected pin in the middle of the map, got %v", c)
+	}
+	if c := m.At(90, 185); !sameColor(c, color.White) {
+		t.Errorf("expected map below the pin, got %v", c)
+	}
+
+	// Tokyo is at the same place as on the world map twice as wide.
+	tokyo := onmap.Coord{35.6895, 139.6917}
+	want := onmap.Mercator.Convert(tokyo, 360, 360).Sub(image.Pt(180, 0))
+	opt.Crop = &onmap.CropOption{Bound: 20}
+	m = onmap.Render(worldMap, pin, []onmap.Coord{tokyo}, opt)
+	if got := m.Bounds().Min.Add(image.Pt(20, 20)); got != want {
+		t.Errorf("expected Tokyo at %v, got %v", want, got)
+	}
+
+	// Coordinates in the western hemisphere are off the map.
+	m = onmap.Render(worldMap, pin, []onmap.Coord{{40.7, -74.0}}, opt)
+	if n := countColor(m, red); n != 0 {
+		t.Errorf("expected no pins, got %d pixels", n)
+	}
+}
@@ -0,0 +1,47 @@
+package onmap
+
+import "math"
+
+// Bounds describes a geographic rectangle by its
+// south-west and north-east corners.
+type Bounds struct {
+	// SW is the south-west corner.
+	SW Coord
+
+	// NE is the north-east corner.
+	NE Coord
+}
+
+// DensestRegion bins coordinates into a latitude/longitude grid with
+// cells of the given size in degrees and returns the bounds of the cell
+// containing the most coordinates and the number of coordinates in it.
+// If several cells have the same count, the one that received
+// a coordinate first wins.
+//
+// Returns zero bounds and count if coords is empty or gridDeg is not positive.
+func DensestRegion(coords []Coord, gridDeg float64) (bounds Bounds, count int) {
+	if gridDeg <= 0 {
+		return Bounds{}, 0
+	}
+	type cell struct{ lat, long int }
+	counts := make(map[cell]int)
+	first := make(map[cell]int) // index of the first coordinate in the cell
+	var best cell
+	for i, c := range coords {
+		k := cell{int(math.Floor(c.Lat / gridDeg)), int(math.Floor(c.Long / gridDeg))}
+		if counts[k] == 0 {
+			first[k] = i
+		}
+		counts[k]++
+		if n := counts[k]; n > count || n == count && first[k] < first[best] {
+			best = k
+			count = n
+		}
+	}
+	if count == 0 {
+		return Bounds{}, 0
+	}
+	bounds.SW = Coord{float64(best.lat) * gridDeg, float64(best.long) * gridDeg}
+	bounds.NE = Coord{float64(best.lat+1) * gridDeg, float64(best.long+1) * gridDeg}
+	return bounds, count
+}
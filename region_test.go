@@ -0,0 +1,40 @@
+package onmap_test
+
+import (
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestDensestRegion(t *testing.T) {
+	coords := []onmap.Coord{
+		{55.755833, 37.617222},   // Moscow
+		{41.9097306, 12.2558141}, // Rome
+		{45.4628329, 9.1076924},  // Milano
+		{43.7800607, 11.170928},  // Florence
+		{44.4949, 11.3426},       // Bologna
+		{37.7775, -122.416389},   // San Francisco
+	}
+	bounds, count := onmap.DensestRegion(coords, 10)
+	if count != 3 {
+		t.Errorf("expected count 3, got %d", count)
+	}
+	expected := onmap.Bounds{SW: onmap.Coord{40, 10}, NE: onmap.Coord{50, 20}}
+	if bounds != expected {
+		t.Errorf("expected bounds %v, got %v", expected, bounds)
+	}
+
+	// Of cells with the same count, the one that received
+	// a coordinate first wins, even if the other one reached
+	// the count first.
+	a, b := onmap.Coord{5, 5}, onmap.Coord{-5, -5}
+	bounds, count = onmap.DensestRegion([]onmap.Coord{a, b, b, a}, 10)
+	expected = onmap.Bounds{SW: onmap.Coord{0, 0}, NE: onmap.Coord{10, 10}}
+	if count != 2 || bounds != expected {
+		t.Errorf("expected bounds %v with count 2, got %v with count %d", expected, bounds, count)
+	}
+
+	if _, count := onmap.DensestRegion(nil, 10); count != 0 {
+		t.Errorf("expected zero count for no coords, got %d", count)
+	}
+}
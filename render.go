@@ -0,0 +1,679 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// Options defines rendering options.
+type Options struct {
+	// Projection is the projection of the world map.
+	// If nil, Mercator is used.
+	Projection Projection
+
+	// MapBounds, if not nil, is the geographic area covered by the world
+	// map, for maps that don't cover the whole world, such as regional
+	// maps. Coordinates are converted with the projection as if the map
+	// was cut out of the world map in it, see Extent.
+	MapBounds *Bounds
+
+	// Crop defines how to crop the image. If nil, doesn't crop the image.
+	Crop *CropOption
+
+	// Background is the color the rendered image is flattened over,
+	// which fills transparent areas of the world map, such as oceans.
+	// If nil, the image keeps the transparency of the world map.
+	Background color.Color
+
+	// Canvas defines the canvas to fit the world map into before
+	// cropping and drawing pins. If nil, the canvas is the world map.
+	Canvas *CanvasOption
+
+	// Cluster defines clustering of nearby pins into a single pin
+	// with a badge showing the number of pins. If nil, pins
+	// are not clustered.
+	Cluster *ClusterOption
+
+	// MergeShadows, if true, composites the first pin parts, which are
+	// usually shadows, as a single layer, so that overlapping
+	// translucent shadows don't darken each other.
+	MergeShadows bool
+
+	// CenterEmphasis, if not nil, emphasizes pins near the center
+	// of the crop rectangle by making peripheral pins smaller
+	// and more transparent.
+	CenterEmphasis *EmphasisOption
+
+	// Target, if not nil, defines the size the final image
+	// is resized to after cropping.
+	Target *TargetOption
+
+	// InputOrder, if true, draws pins in the order of coordinates,
+	// so that later pins are on top, instead of sorting them by
+	// latitude to draw lower pins on top of upper pins.
+	InputOrder bool
+
+	// CenterOrder, if true, draws pins in the order of decreasing
+	// distance from the centroid of their points, so that the most
+	// central pin is on top, instead of sorting them by latitude.
+	// It's ignored if InputOrder is set.
+	CenterOrder bool
+
+	// Subpixel, if true, positions pins at fractional pixel coordinates
+	// instead of rounding points to whole pixels, resampling pin parts
+	// with anti-aliased edges, so that pins move smoothly between
+	// frames of animations. It's ignored if Rotation is set.
+	Subpixel bool
+
+	// Legend, if not nil, is drawn over the image in its corner.
+	Legend *LegendOverlay
+
+	// Border, if not nil, is drawn along the edges of the final image
+	// after cropping and resizing, inside the image bounds.
+	Border *BorderOption
+
+	// Rotation, if not nil, rotates the map about the center coordinate
+	// so that the bearing points up, for example, for navigation views.
+	// Pins are not rotated, and the crop is computed on the rotated map.
+	// The canvas keeps its size, so parts of the rotated map outside
+	// of it are cut off, and areas of the canvas not covered by the map
+	// are transparent.
+	Rotation *RotationOption
+
+	// Graticule, if not nil, draws latitude and longitude lines over the map.
+	Graticule *GraticuleOption
+
+	// Alpha, if not zero, is the opacity of pins from 0 to 1
+	// applied to all pin parts, including shadows.
+	Alpha float64
+
+	// Scale, if not zero, renders the image at the given scale, for example,
+	// 2 for high-DPI displays: the world map, pin parts, and sizes in pixels
+	// in options are all scaled by it, so that the image has the same
+	// content and is Scale times larger. Functions based on Render scale
+	// their lines and margins too, but text drawn with fonts, such as
+	// glyphs and legends, is not scaled.
+	Scale float64
+
+	// Anchors define positions of pin parts relative to the point:
+	// Anchors[i] is used for the i-th pin part. Pin parts without
+	// anchors are positioned with their bottom center at the point.
+	Anchors []Anchor
+}
+
+// Anchor defines the position of a pin part relative to the point.
+type Anchor struct {
+	// Tip is the pixel of the pin part image, relative to the top-left
+	// corner of the image, that is placed at the point, for example,
+	// the tip of a callout balloon.
+	Tip image.Point
+
+	// Offset moves the pin part image from the point, for example,
+	// to draw the body of a balloon up and to the left of its tip.
+	Offset image.Point
+}
+
+// AnchorsAt returns anchors that place each of the pin parts with
+// the point at the given fraction of its width and height, for example,
+// (0.5, 0.5) for the center and (0, 1) for the bottom-left corner.
+// Pin parts are positioned at (0.5, 1), the bottom center, by default.
+func AnchorsAt(parts []image.Image, ax, ay float64) []Anchor {
+	anchors := make([]Anchor, len(parts))
+	for i, p := range parts {
+		s := p.Bounds().Size()
+		anchors[i].Tip = image.Point{
+			int(math.Floor(ax * float64(s.X))),
+			int(math.Floor(ay * float64(s.Y))),
+		}
+	}
+	return anchors
+}
+
+func (o *Options) projection() Projection {
+	if o == nil {
+		return Mercator
+	}
+	p := o.Projection
+	if p == nil {
+		p = Mercator
+	}
+	if b := o.MapBounds; b != nil {
+		p = Extent(p, Coord{b.NE.Lat, b.SW.Long}, Coord{b.SW.Lat, b.NE.Long})
+	}
+	return p
+}
+
+func (o *Options) anchors() []Anchor {
+	if o == nil {
+		return nil
+	}
+	return o.Anchors
+}
+
+// pinMask returns the mask to draw pin parts with, or nil if they are opaque.
+func (o *Options) pinMask() image.Image {
+	if o == nil || o.Alpha == 0 || o.Alpha >= 1 {
+		return nil
+	}
+	return image.NewUniform(color.Alpha16{uint16(math.Max(0, o.Alpha) * 0xffff)})
+}
+
+func (o *Options) crop() *CropOption {
+	if o == nil {
+		return nil
+	}
+	return o.Crop
+}
+
+// Render returns an image with the given coordinates marked as pins
+// on the given world map according to options. If opt is nil,
+// uses Mercator projection and doesn't crop the image.
+//
+// See MapPinsProjection for the description of pin parts.
+func Render(worldMap image.Image, pinParts []image.Image, coords []Coord, opt *Options) image.Image {
+	m, r := render(worldMap, pinParts, coords, opt)
+	return finish(m, r, opt)
+}
+
+// RenderPair is like Render, but returns two versions of the image from
+// a single rendering pass: transparent keeps the transparency of the
+// world map, while baked is flattened over opt.Background (or white
+// if it's nil), which is useful for formats without alpha channel.
+func RenderPair(worldMap image.Image, pinParts []image.Image, coords []Coord, opt *Options) (transparent, baked image.Image) {
+	m, r := render(worldMap, pinParts, coords, opt)
+	var bg color.Color = color.White
+	if opt != nil && opt.Background != nil {
+		bg = opt.Background
+	}
+	baked = opt.frame(opt.resize(subImage(flatten(m, bg), r)))
+	return opt.frame(opt.resize(subImage(m, r))), baked
+}
+
+// RenderZ is like Render, but draws pins in ascending order of their Z
+// values, so that pins with higher Z are drawn on top. Pins with equal
+// Z are ordered by latitude as usual.
+func RenderZ(worldMap image.Image, pinParts []image.Image, pins []PinCoord, opt *Options) image.Image {
+	coords := make([]Coord, len(pins))
+	for i, p := range pins {
+		coords[i] = p.Coord
+	}
+	m, r := renderMarkers(worldMap, coords, opt, func(i int, pt image.Point) marker {
+		return marker{pt: pt, parts: pinParts, z: pins[i].Z}
+	})
+	return finish(m, r, opt)
+}
+
+// PinCoord is a coordinate with the stacking order of its pin.
+type PinCoord struct {
+	Coord
+
+	// Z is the stacking order: pins with higher Z are drawn on top.
+	Z float64
+}
+
+// render draws the world map with pins and returns
+// the resulting image and its crop rectangle.
+func render(worldMap image.Image, pinParts []image.Image, coords []Coord, opt *Options) (*image.RGBA, image.Rectangle) {
+	return renderMarkers(worldMap, coords, opt, func(i int, pt image.Point) marker {
+		return marker{pt: pt, parts: pinParts}
+	})
+}
+
+// renderMarkers draws the world map with markers made by mk for
+// each coordinate and returns the resulting image and its crop rectangle.
+func renderMarkers(worldMap image.Image, coords []Coord, opt *Options, mk func(i int, pt image.Point) marker) (*image.RGBA, image.Rectangle) {
+	m, r, _ := renderMarkersContext(context.Background(), worldMap, coords, opt, mk)
+	return m, r
+}
+
+// renderMarkersContext is like renderMarkers, but stops rendering
+// and returns the context error if the context is done.
+func renderMarkersContext(ctx context.Context, worldMap image.Image, coords []Coord, opt *Options, mk func(i int, pt image.Point) marker) (*image.RGBA, image.Rectangle, error) {
+	return renderScene(ctx, worldMap, opt, &scene{coords: coords, mk: mk})
+}
+
+// makeMarkers returns markers made by mk for points according to options
+// and badges to draw over them. Markers that don't overlap the crop
+// rectangle r are skipped, before clustering them if it's enabled.
+func makeMarkers(cs []image.Point, r image.Rectangle, opt *Options, mk func(i int, pt image.Point) marker) ([]marker, []badge) {
+	if opt != nil && opt.CenterOrder {
+		mk = centerMarkers(cs, mk)
+	}
+	// shown returns the marker of the pin at the point
+	// and reports whether it's drawn.
+	shown := func(i int, c image.Point) (marker, bool) {
+		if crop := opt.crop(); crop != nil && crop.FixedBounds != nil && !c.In(r) {
+			// Drop pins outside of the fixed window.
+			return marker{}, false
+		}
+		// Skip pins that are not visible.
+		m := mk(i, c)
+		return m, m.bounds(opt.anchors()).Overlaps(r)
+	}
+	var markers []marker
+	var badges []badge
+	if opt != nil && opt.Cluster != nil {
+		// Cluster only shown pins, so that others don't move
+		// clusters or count in their badges.
+		var idx []int
+		var pts []image.Point
+		for i, c := range cs {
+			if _, ok := shown(i, c); ok {
+				idx = append(idx, i)
+				pts = append(pts, c)
+			}
+		}
+		markers, badges = clusterMarkers(pts, opt.Cluster.radius(r), func(j int, pt image.Point) marker {
+			return mk(idx[j], pt)
+		})
+	} else {
+		markers = make([]marker, 0, len(cs))
+		for i, c := range cs {
+			if m, ok := shown(i, c); ok {
+				markers = append(markers, m)
+			}
+		}
+	}
+	if opt != nil && opt.CenterEmphasis != nil {
+		opt.CenterEmphasis.apply(markers, r)
+	}
+	return markers, badges
+}
+
+// centerMarkers returns the marker maker that sets distances of markers
+// made by mk from the centroid of all points, so that the order of pins
+// doesn't depend on which of them are visible.
+func centerMarkers(cs []image.Point, mk func(i int, pt image.Point) marker) func(i int, pt image.Point) marker {
+	var cx, cy float64
+	for _, c := range cs {
+		cx += float64(c.X)
+		cy += float64(c.Y)
+	}
+	if len(cs) > 0 {
+		cx /= float64(len(cs))
+		cy /= float64(len(cs))
+	}
+	return func(i int, pt image.Point) marker {
+		m := mk(i, pt)
+		m.dist = math.Hypot(float64(pt.X)-cx, float64(pt.Y)-cy)
+		return m
+	}
+}
+
+func (o *Options) badgeColor() color.Color {
+	if o == nil || o.Cluster == nil {
+		return nil
+	}
+	return colorOr(o.Cluster.BadgeColor, color.Gray{0x30})
+}
+
+// finish flattens the rendered image according to options and crops it.
+func finish(m *image.RGBA, r image.Rectangle, opt *Options) image.Image {
+	if opt != nil && opt.Background != nil {
+		m = flatten(m, opt.Background)
+	}
+	return opt.frame(opt.resize(subImage(m, r)))
+}
+
+// resize resizes the image according to opt.Target, if it's set.
+func (o *Options) resize(m image.Image) image.Image {
+	if o == nil || o.Target == nil {
+		return m
+	}
+	return o.Target.resize(m)
+}
+
+// frame draws the border around the image according to opt.Border,
+// if it's set, scaling its width by opt.Scale.
+func (o *Options) frame(m image.Image) image.Image {
+	if o == nil || o.Border == nil {
+		return m
+	}
+	b := *o.Border
+	if o.Scale != 0 {
+		b.Width = scaleInt(b.width(), o.Scale)
+	}
+	return b.draw(m)
+}
+
+// layoutPoints returns the coordinates converted to points on the canvas
+// and the layout of the map on the canvas according to options.
+func layoutPoints(worldMap image.Image, coords []Coord, opt *Options) ([]image.Point, layout) {
+	l := layout{proj: opt.projection()}
+	if opt != nil && opt.Canvas != nil {
+		l.canvas = image.Rect(0, 0, opt.Canvas.Width, opt.Canvas.Height)
+		l.mapRect = opt.Canvas.mapRect(worldMap)
+	} else {
+		l.mapRect = image.Rect(0, 0, worldMap.Bounds().Dx(), worldMap.Bounds().Dy())
+		l.canvas = l.mapRect
+	}
+	cs := make([]image.Point, len(coords))
+	for i, c := range coords {
+		cs[i] = l.point(c)
+	}
+	if crop := opt.crop(); crop != nil && crop.WrapAntimeridian && crop.FixedBounds == nil {
+		if l.shift = wrapShift(cs, l.mapRect); l.shift != 0 {
+			for i, c := range coords {
+				cs[i] = l.point(c)
+			}
+		}
+	}
+	return cs, l
+}
+
+// draw returns a new image of the part r of the canvas
+// with the layers of the world map drawn on it.
+func (l layout) draw(worldMap image.Image, opt *Options, r image.Rectangle, layers sceneLayer) *image.RGBA {
+	m := image.NewRGBA(r)
+	l.drawMap(m, worldMap, opt, layers)
+	return m
+}
+
+// drawMap draws the layers of the world map on the transparent image
+// of a part of the canvas: the map with the canvas background, and the
+// graticule. If the map is resized to fit the canvas, only the part
+// of it inside the image is resized.
+func (l layout) drawMap(m *image.RGBA, worldMap image.Image, opt *Options, layers sceneLayer) {
+	if layers&sceneMap != 0 {
+		// Copying the map onto the transparent image is faster
+		// than compositing it, which is needed over the background.
+		op := draw.Src
+		if opt != nil && opt.Canvas != nil && opt.Canvas.Background != nil {
+			draw.Draw(m, m.Bounds(), image.NewUniform(opt.Canvas.Background), image.Point{}, draw.Src)
+			op = draw.Over
+		}
+		w, h := l.mapRect.Dx(), l.mapRect.Dy()
+		r := m.Rect.Sub(l.mapRect.Min)
+		if l.shift != 0 {
+			// Rolled map wraps around, so take whole rows.
+			r.Min.X, r.Max.X = 0, w
+		}
+		if r = r.Intersect(image.Rect(0, 0, w, h)); !r.Empty() {
+			src, sp := region(worldMap, w, h, r)
+			drawRolled(m, l.mapRect, src, sp, l.shift, op)
+		}
+	}
+	if layers&sceneLines != 0 && opt != nil && opt.Graticule != nil {
+		opt.Graticule.draw(m, l)
+	}
+}
+
+// layout describes the placement of the world map on the canvas.
+type layout struct {
+	// proj is the projection of the map.
+	proj Projection
+
+	// mapRect is the rectangle of the canvas occupied by the map.
+	mapRect image.Rectangle
+
+	// canvas is the rectangle of the canvas.
+	canvas image.Rectangle
+
+	// shift is the number of pixels the map is rolled
+	// horizontally by, wrapping around its edges.
+	shift int
+
+	// scale is the scale of rendering, see Options.Scale.
+	// If zero, the rendering is not scaled.
+	scale float64
+}
+
+// px returns the size in pixels scaled by the scale of rendering.
+func (l layout) px(v float64) float64 {
+	if l.scale == 0 {
+		return v
+	}
+	return v * l.scale
+}
+
+// point converts the coordinate to a point on the canvas.
+func (l layout) point(c Coord) image.Point {
+	p := l.proj.Convert(c, l.mapRect.Dx(), l.mapRect.Dy())
+	if l.shift != 0 {
+		p.X = mod(p.X+l.shift, l.mapRect.Dx())
+	}
+	return p.Add(l.mapRect.Min)
+}
+
+// cropRect returns the crop rectangle of the canvas for the given
+// points or the whole canvas if crop is nil.
+func (l layout) cropRect(cs []image.Point, crop *CropOption) image.Rectangle {
+	if crop == nil {
+		return l.canvas
+	}
+	if b := crop.FixedBounds; b != nil {
+		nw := l.point(Coord{b.NE.Lat, b.SW.Long})
+		se := l.point(Coord{b.SW.Lat, b.NE.Long})
+		return image.Rectangle{nw, se}.Canon().Intersect(l.canvas)
+	}
+	return cropRect(cs, l.canvas.Dx(), l.canvas.Dy(), crop)
+}
+
+// project converts coordinates to points on the map of the given size.
+func project(proj Projection, coords []Coord, mapWidth, mapHeight int) []image.Point {
+	cs := make([]image.Point, len(coords))
+	for i, c := range coords {
+		cs[i] = proj.Convert(c, mapWidth, mapHeight)
+	}
+	return cs
+}
+
+// visible returns points of the coordinates that are not hidden
+// by the projection of the layout, see Orthographic.
+func (l layout) visible(coords []Coord, cs []image.Point) []image.Point {
+	if _, ok := l.proj.(hider); !ok {
+		return cs
+	}
+	visible := make([]image.Point, 0, len(cs))
+	for i, c := range coords {
+		if !isHidden(l.proj, c) {
+			visible = append(visible, cs[i])
+		}
+	}
+	return visible
+}
+
+// cropRect returns the crop rectangle for the given points on the
+// canvas with the layout or the whole canvas if there is no crop option.
+func (o *Options) cropRect(cs []image.Point, l layout) image.Rectangle {
+	return l.cropRect(cs, o.crop())
+}
+
+// marker is a point on the map with pin parts to draw at it.
+type marker struct {
+	pt      image.Point
+	parts   []image.Image
+	anchors []Anchor
+	z       float64
+
+	// sub is the subpixel offset of the point from pt.
+	sub subpixel
+
+	// dist is the distance of the point from the centroid
+	// of all points, see Options.CenterOrder.
+	dist float64
+}
+
+// partRect returns the rectangle of the i-th pin part of the marker.
+func (mk marker) partRect(i int) image.Rectangle {
+	part := mk.parts[i]
+	if i >= len(mk.anchors) {
+		return partRect(mk.pt, part)
+	}
+	a := mk.anchors[i]
+	min := mk.pt.Sub(a.Tip).Add(a.Offset)
+	return image.Rectangle{min, min.Add(part.Bounds().Size())}
+}
+
+// bounds returns the bounding rectangle of all pin parts of the marker,
+// using the given anchors if the marker has none.
+func (mk marker) bounds(anchors []Anchor) image.Rectangle {
+	if mk.anchors == nil {
+		mk.anchors = anchors
+	}
+	var r image.Rectangle
+	for i := range mk.parts {
+		r = r.Union(mk.partRect(i))
+	}
+	return r
+}
+
+// drawMarkers draws markers on the image according to options.
+func drawMarkers(m draw.Image, markers []marker, opt *Options) {
+	drawMarkerParts(context.Background(), m, markers, opt, 0, -1)
+}
+
+// drawMarkerParts draws pin parts of markers with indexes from first
+// to last (exclusive, or all if it's negative) on the image.
+// It stops and returns the context error if the context is done
+// before drawing a layer of pin parts.
+func drawMarkerParts(ctx context.Context, m draw.Image, markers []marker, opt *Options, first, last int) error {
+	sorted := sortMarkers(markers, opt)
+	// Skip markers outside of the image, for example,
+	// for coordinates outside of regional maps.
+	visible := sorted[:0]
+	for _, mk := range sorted {
+		if mk.bounds(nil).Overlaps(m.Bounds()) {
+			visible = append(visible, mk)
+		}
+	}
+	sorted = visible
+	layers := 0
+	for _, mk := range sorted {
+		if len(mk.parts) > layers {
+			layers = len(mk.parts)
+		}
+	}
+	if last >= 0 && last < layers {
+		layers = last
+	}
+
+	// Draw pin parts.
+	// Looping over pin parts first to better arrange shadows.
+	mask := opt.pinMask()
+	for i := first; i < layers; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if i == 0 && opt != nil && opt.MergeShadows {
+			drawMergedParts(m, sorted, 0, mask)
+			continue
+		}
+		for _, mk := range sorted {
+			if i >= len(mk.parts) {
+				continue
+			}
+			pin, r := mk.drawnPart(i)
+			draw.DrawMask(m, r, pin, pin.Bounds().Min, mask, image.Point{}, draw.Over)
+		}
+	}
+	return nil
+}
+
+// sortMarkers returns a copy of markers with options applied in the order
+// of drawing: by Z and then by latitude so that lower pins are drawn
+// on top of upper pins, unless opt.InputOrder or opt.CenterOrder is set.
+func sortMarkers(markers []marker, opt *Options) []marker {
+	sorted := make([]marker, len(markers))
+	copy(sorted, markers)
+	if opt != nil && opt.Anchors != nil {
+		for i := range sorted {
+			if sorted[i].anchors == nil {
+				sorted[i].anchors = opt.Anchors
+			}
+		}
+	}
+	inputOrder := opt != nil && opt.InputOrder
+	centerOrder := opt != nil && opt.CenterOrder && !inputOrder
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].z != sorted[j].z {
+			return sorted[i].z < sorted[j].z
+		}
+		if inputOrder {
+			return false
+		}
+		if centerOrder {
+			if di, dj := sorted[i].dist, sorted[j].dist; di != dj {
+				return di > dj
+			}
+		}
+		if sorted[i].pt.Y != sorted[j].pt.Y {
+			return sorted[i].pt.Y < sorted[j].pt.Y
+		}
+		return sorted[i].pt.X < sorted[j].pt.X
+	})
+	return sorted
+}
+
+// drawMergedParts draws the i-th parts of markers into a separate layer
+// keeping the maximum alpha for overlapping pixels, and then draws
+// this layer onto the image with the mask, which may be nil.
+func drawMergedParts(m draw.Image, markers []marker, i int, mask image.Image) {
+	layer := image.NewRGBA(m.Bounds())
+	for _, mk := range markers {
+		if i >= len(mk.parts) {
+			continue
+		}
+		part, pr := mk.drawnPart(i)
+		r := pr.Intersect(layer.Rect)
+		sp := part.Bounds().Min.Sub(pr.Min)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				c := color.RGBA64Model.Convert(part.At(x+sp.X, y+sp.Y)).(color.RGBA64)
+				if _, _, _, a := layer.At(x, y).RGBA(); uint32(c.A) > a {
+					layer.Set(x, y, c)
+				}
+			}
+		}
+	}
+	draw.DrawMask(m, m.Bounds(), layer, m.Bounds().Min, mask, image.Point{}, draw.Over)
+}
+
+// partRect returns the rectangle of the pin part drawn at the point,
+// which is at the bottom center of the part.
+func partRect(pt image.Point, part image.Image) image.Rectangle {
+	halfw := part.Bounds().Dx() / 2
+	h := part.Bounds().Dy()
+	return image.Rect(pt.X-halfw, pt.Y-h, pt.X+halfw, pt.Y)
+}
+
+// pinRect returns the bounding rectangle of all pin parts drawn at the point.
+func pinRect(pt image.Point, parts []image.Image) image.Rectangle {
+	var r image.Rectangle
+	for _, p := range parts {
+		r = r.Union(partRect(pt, p))
+	}
+	return r
+}
+
+// flatten returns a copy of the image drawn over the background color.
+func flatten(m *image.RGBA, bg color.Color) *image.RGBA {
+	dst := image.NewRGBA(m.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), m, m.Bounds().Min, draw.Over)
+	return dst
+}
+
+// subImager is implemented by images that can return their parts.
+type subImager interface {
+	SubImage(r image.Rectangle) image.Image
+}
+
+// subImage returns the part of the image inside r,
+// or the image itself if r covers all of it.
+// Images that don't implement SubImage are copied.
+func subImage(m image.Image, r image.Rectangle) image.Image {
+	if r == m.Bounds() {
+		return m
+	}
+	if si, ok := m.(subImager); ok {
+		return si.SubImage(r)
+	}
+	dst := image.NewRGBA(r.Intersect(m.Bounds()))
+	draw.Draw(dst, dst.Bounds(), m, dst.Bounds().Min, draw.Src)
+	return dst
+}
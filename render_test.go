@@ -0,0 +1,590 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderPair(t *testing.T) {
+	// Transparent map with an opaque land in the middle.
+	worldMap := image.NewRGBA(image.Rect(0, 0, 360, 360))
+	land := color.RGBA{0x20, 0x80, 0x20, 0xff}
+	for y := 100; y < 260; y++ {
+		for x := 100; x < 260; x++ {
+			worldMap.Set(x, y, land)
+		}
+	}
+	bg := color.RGBA{0x10, 0x30, 0x90, 0xff}
+	coords := []onmap.Coord{{0, 0}}
+	transparent, baked := onmap.RenderPair(worldMap, onmap.DefaultPin(), coords, &onmap.Options{Background: bg})
+
+	corners := []image.Point{{0, 0}, {359, 0}, {0, 359}, {359, 359}}
+	for _, p := range corners {
+		if _, _, _, a := transparent.At(p.X, p.Y).RGBA(); a != 0 {
+			t.Errorf("transparent: expected transparent corner at %v, got alpha %d", p, a)
+		}
+		if c := baked.At(p.X, p.Y); !sameColor(c, bg) {
+			t.Errorf("baked: expected background at %v, got %v", p, c)
+		}
+	}
+	if c := baked.At(110, 110); !sameColor(c, land) {
+		t.Errorf("baked: expected land at (110, 110), got %v", c)
+	}
+}
+
+func TestRenderBackground(t *testing.T) {
+	// Semi-transparent blue map.
+	worldMap := solidMap(360, 360, color.NRGBA{0, 0, 0xff, 0x80})
+	coords := []onmap.Coord{{0, 0}}
+
+	m := onmap.Render(worldMap, onmap.DefaultPin(), coords, &onmap.Options{Background: color.White})
+	r, g, b, a := m.At(10, 10).RGBA()
+	if a != 0xffff || r>>8 < 0x70 || r>>8 > 0x80 || g != r || b != 0xffff {
+		t.Errorf("expected light blue with the background showing through, got %v", m.At(10, 10))
+	}
+
+	// Without background, the map keeps its transparency.
+	m = onmap.Render(worldMap, onmap.DefaultPin(), coords, nil)
+	if _, _, _, a := m.At(10, 10).RGBA(); a>>8 != 0x80 {
+		t.Errorf("expected semi-transparent map, got alpha %d", a>>8)
+	}
+
+	// Background doesn't change opaque maps.
+	opaque := solidMap(360, 360, color.RGBA{0x20, 0x80, 0x20, 0xff})
+	m = onmap.Render(opaque, onmap.DefaultPin(), coords, &onmap.Options{Background: color.White})
+	if c := m.At(10, 10); !sameColor(c, opaque.At(10, 10)) {
+		t.Errorf("expected unchanged opaque map, got %v", c)
+	}
+}
+
+func TestRenderZ(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	// Pin with the black bottom row.
+	pin := solidMap(20, 20, color.RGBA{0xff, 0, 0, 0xff})
+	for x := 0; x < 20; x++ {
+		pin.Set(x, 19, color.Black)
+	}
+	// The second pin is lower on the map, so it would normally be drawn
+	// on top, but the first one has higher Z.
+	pins := []onmap.PinCoord{
+		{Coord: onmap.Coord{10, 0}, Z: 1},
+		{Coord: onmap.Coord{0, 0}, Z: 0},
+	}
+	m := onmap.RenderZ(worldMap, []image.Image{pin}, pins, nil)
+	p := onmap.Mercator.Convert(pins[0].Coord, 360, 360)
+	if c := m.At(p.X, p.Y-1); !sameColor(c, color.Black) {
+		t.Errorf("expected higher Z pin on top at %v, got %v", p, c)
+	}
+
+	// Without Z the lower pin is on top.
+	pins[0].Z = 0
+	m = onmap.RenderZ(worldMap, []image.Image{pin}, pins, nil)
+	if c := m.At(p.X, p.Y-1); sameColor(c, color.Black) {
+		t.Errorf("expected lower pin on top at %v, got %v", p, c)
+	}
+}
+
+func TestRenderFixedBounds(t *testing.T) {
+	// Map with a unique color for each pixel to check alignment.
+	worldMap := image.NewRGBA(image.Rect(0, 0, 360, 360))
+	for y := 0; y < 360; y++ {
+		for x := 0; x < 360; x++ {
+			worldMap.Set(x, y, color.RGBA{uint8(x), uint8(y), uint8(x / 256 * 2), 0xff})
+		}
+	}
+	opt := &onmap.Options{
+		Crop: &onmap.CropOption{
+			FixedBounds: &onmap.Bounds{SW: onmap.Coord{-30, -40}, NE: onmap.Coord{30, 40}},
+		},
+	}
+	m1 := onmap.Render(worldMap, onmap.DefaultPin(), []onmap.Coord{{0, 0}}, opt)
+	m2 := onmap.Render(worldMap, onmap.DefaultPin(), []onmap.Coord{{10, 10}, {20, -30}, {-60, 100}}, opt)
+	if m1.Bounds() != m2.Bounds() {
+		t.Fatalf("expected identical bounds, got %v and %v", m1.Bounds(), m2.Bounds())
+	}
+	b := m1.Bounds()
+	if c1, c2 := m1.At(b.Min.X, b.Min.Y), m2.At(b.Min.X, b.Min.Y); !sameColor(c1, c2) {
+		t.Errorf("expected aligned maps, got %v and %v at the corner", c1, c2)
+	}
+	expected := image.Rectangle{
+		onmap.Mercator.Convert(onmap.Coord{30, -40}, 360, 360),
+		onmap.Mercator.Convert(onmap.Coord{-30, 40}, 360, 360),
+	}
+	if b != expected {
+		t.Errorf("expected bounds %v, got %v", expected, b)
+	}
+}
+
+func TestRenderMergeShadows(t *testing.T) {
+	worldMap := image.NewRGBA(image.Rect(0, 0, 360, 360))
+	shadow := solidMap(20, 10, color.RGBA{0, 0, 0, 0x80})
+	coords := []onmap.Coord{{0, 0}, {0, 5}}
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+	overlap := image.Point{p.X + 5, p.Y - 5}
+
+	naive := onmap.Render(worldMap, []image.Image{shadow}, coords, nil)
+	merged := onmap.Render(worldMap, []image.Image{shadow}, coords, &onmap.Options{MergeShadows: true})
+	_, _, _, na := naive.At(overlap.X, overlap.Y).RGBA()
+	_, _, _, ma := merged.At(overlap.X, overlap.Y).RGBA()
+	if ma >= na {
+		t.Errorf("expected merged shadow overlap to be lighter: naive alpha %d, merged alpha %d", na, ma)
+	}
+	if ma != 0x8080 {
+		t.Errorf("expected merged shadow overlap to have alpha of a single shadow, got %d", ma)
+	}
+}
+
+func TestRenderAnchors(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	tip := solidMap(3, 3, red)
+	balloon := solidMap(30, 20, blue)
+	opt := &onmap.Options{
+		Anchors: []onmap.Anchor{
+			{Tip: image.Point{1, 1}},
+			{Offset: image.Point{-40, -30}},
+		},
+	}
+	m := onmap.Render(worldMap, []image.Image{tip, balloon}, []onmap.Coord{{0, 0}}, opt)
+	p := image.Point{180, 180}
+	if c := m.At(p.X, p.Y); !sameColor(c, red) {
+		t.Errorf("expected tip at %v, got %v", p, c)
+	}
+	body := image.Rect(-40, -30, -10, -10).Add(p)
+	for _, q := range []image.Point{body.Min, body.Max.Sub(image.Point{1, 1})} {
+		if c := m.At(q.X, q.Y); !sameColor(c, blue) {
+			t.Errorf("expected balloon body at %v, got %v", q, c)
+		}
+	}
+	if c := m.At(p.X, p.Y-5); !sameColor(c, color.White) {
+		t.Errorf("expected no balloon above the tip, got %v", c)
+	}
+}
+
+func TestRenderScale(t *testing.T) {
+	worldMap := solidMap(720, 720, color.White)
+	coords := []onmap.Coord{{0, 0}, {0, 30}}
+	for _, crop := range []*onmap.CropOption{nil, {Bound: 50, MinWidth: 300, MinHeight: 200}} {
+		m1 := onmap.Render(worldMap, onmap.DefaultPin(), coords, &onmap.Options{Crop: crop})
+		m2 := onmap.Render(worldMap, onmap.DefaultPin(), coords, &onmap.Options{Crop: crop, Scale: 2})
+		if s1, s2 := m1.Bounds().Size(), m2.Bounds().Size(); s2 != s1.Mul(2) {
+			t.Errorf("crop %v: expected size %v, got %v", crop, s1.Mul(2), s2)
+		}
+		if n1, n2 := countNotColor(m1, color.White), countNotColor(m2, color.White); n2 < n1*3 || n2 > n1*5 {
+			t.Errorf("crop %v: expected pins four times larger in area, got %d and %d pixels", crop, n1, n2)
+		}
+	}
+}
+
+func TestRenderAlpha(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	pin := solidMap(10, 10, red)
+	coords := []onmap.Coord{{0, 0}}
+	p := onmap.Mercator.Convert(coords[0], 360, 360)
+
+	opaque := onmap.Render(worldMap, []image.Image{pin}, coords, &onmap.Options{Alpha: 1})
+	if c := opaque.At(p.X, p.Y-5); !sameColor(c, red) {
+		t.Errorf("expected opaque pin color %v, got %v", red, c)
+	}
+
+	half := onmap.Render(worldMap, []image.Image{pin}, coords, &onmap.Options{Alpha: 0.5})
+	r, g, b, _ := half.At(p.X, p.Y-5).RGBA()
+	if r != 0xffff || g>>8 < 0x7e || g>>8 > 0x81 || b != g {
+		t.Errorf("expected half-transparent pin over white, got %v", half.At(p.X, p.Y-5))
+	}
+}
+
+// randomCoords returns n pseudo-random coordinates within the bounds.
+func randomCoords(n int, b onmap.Bounds) []onmap.Coord {
+	rnd := rand.New(rand.NewSource(1))
+	coords := make([]onmap.Coord, n)
+	for i := range coords {
+		coords[i] = onmap.Coord{
+			Lat:  b.SW.Lat + rnd.Float64()*(b.NE.Lat-b.SW.Lat),
+			Long: b.SW.Long + rnd.Float64()*(b.NE.Long-b.SW.Long),
+		}
+	}
+	return coords
+}
+
+func TestRenderVisiblePins(t *testing.T) {
+	worldMap := onmap.DefaultMap()
+	coords := randomCoords(500, onmap.Bounds{SW: onmap.Coord{35, -10}, NE: onmap.Coord{60, 30}})
+	window := &onmap.Bounds{SW: onmap.Coord{44, 5}, NE: onmap.Coord{50, 15}}
+	m := onmap.Render(worldMap, onmap.DefaultPin(), coords, &onmap.Options{
+		Crop: &onmap.CropOption{FixedBounds: window},
+	})
+
+	// Render all pins inside the window on the whole map and crop.
+	b := m.Bounds()
+	size := worldMap.Bounds().Size()
+	var inside []onmap.Coord
+	for _, c := range coords {
+		if onmap.Mercator.Convert(c, size.X, size.Y).In(b) {
+			inside = append(inside, c)
+		}
+	}
+	full := onmap.Render(worldMap, onmap.DefaultPin(), inside, nil)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c1, c2 := m.At(x, y), full.At(x, y); !sameColor(c1, c2) {
+				t.Fatalf("expected %v at (%d, %d), got %v", c2, x, y, c1)
+			}
+		}
+	}
+}
+
+func BenchmarkRenderManyPins(b *testing.B) {
+	worldMap := onmap.DefaultMap()
+	coords := randomCoords(50000, onmap.Bounds{SW: onmap.Coord{-60, -180}, NE: onmap.Coord{70, 180}})
+	b.Run("Full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			onmap.Render(worldMap, onmap.DefaultPin(), coords, nil)
+		}
+	})
+	b.Run("Tight", func(b *testing.B) {
+		opt := &onmap.Options{Crop: &onmap.CropOption{
+			FixedBounds: &onmap.Bounds{SW: onmap.Coord{41.5, 12}, NE: onmap.Coord{42.5, 13}},
+		}}
+		for i := 0; i < b.N; i++ {
+			onmap.Render(worldMap, onmap.DefaultPin(), coords, opt)
+		}
+	})
+}
+
+func TestRenderCropMatchesFull(t *testing.T) {
+	worldMap := onmap.DefaultMap()
+	coords := []onmap.Coord{
+		{42.1, 19.1},             // Bar
+		{41.9097306, 12.2558141}, // Rome
+		{45.4628329, 9.1076924},  // Milano
+		{-80, 10},                // near the south edge
+	}
+	tests := []struct {
+		name   string
+		coords []onmap.Coord
+		opt    *onmap.Options
+	}{
+		{"standard", coords[:3], &onmap.Options{Crop: onmap.StandardCrop}},
+		{"bound", coords[:2], &onmap.Options{Crop: &onmap.CropOption{Bound: 20}}},
+		{"edge", coords[3:], &onmap.Options{Crop: onmap.StandardCrop}},
+		{"canvas", coords[:3], &onmap.Options{
+			Crop:   &onmap.CropOption{Bound: 30},
+			Canvas: &onmap.CanvasOption{Width: 800, Height: 800, Background: color.White},
+		}},
+		{"background", coords[:3], &onmap.Options{Crop: onmap.StandardCrop, Background: color.Black}},
+		{"scale", coords[:3], &onmap.Options{Crop: onmap.StandardCrop, Scale: 1.5}},
+		{"rotation", coords[:3], &onmap.Options{
+			Crop:     onmap.StandardCrop,
+			Rotation: &onmap.RotationOption{Bearing: 30, Center: coords[0]},
+		}},
+		{"all", coords[:3], &onmap.Options{
+			Crop:     &onmap.CropOption{Bound: 30},
+			Canvas:   &onmap.CanvasOption{Width: 800, Height: 800, Background: color.White},
+			Scale:    2,
+			Rotation: &onmap.RotationOption{Bearing: -45, Center: coords[1]},
+		}},
+	}
+	for _, tt := range tests {
+		m := onmap.Render(worldMap, onmap.DefaultPin(), tt.coords, tt.opt)
+		noCrop := *tt.opt
+		noCrop.Crop = nil
+		full := onmap.Render(worldMap, onmap.DefaultPin(), tt.coords, &noCrop)
+		b := m.Bounds()
+		if !b.In(full.Bounds()) {
+			t.Fatalf("%s: crop %v is outside of %v", tt.name, b, full.Bounds())
+		}
+		// Only the cropped part is allocated.
+		if rgba, ok := m.(*image.RGBA); !ok || len(rgba.Pix) != b.Dx()*b.Dy()*4 {
+			t.Errorf("%s: expected image of the crop size", tt.name)
+		}
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if c1, c2 := m.At(x, y), full.At(x, y); !sameColor(c1, c2) {
+					t.Fatalf("%s: expected %v at (%d, %d), got %v", tt.name, c2, x, y, c1)
+				}
+			}
+		}
+	}
+}
+
+// plainImage is an image without the SubImage method.
+type plainImage struct {
+	image.Image
+}
+
+func TestSubImageFallback(t *testing.T) {
+	src := solidMap(100, 100, color.White)
+	src.Set(20, 30, color.Black)
+	r := image.Rect(10, 20, 50, 60)
+	m := onmap.SubImage(plainImage{src}, r)
+	if m.Bounds() != r {
+		t.Fatalf("expected bounds %v, got %v", r, m.Bounds())
+	}
+	if c := m.At(20, 30); !sameColor(c, color.Black) {
+		t.Errorf("expected black at (20, 30), got %v", c)
+	}
+	if c := m.At(21, 30); !sameColor(c, color.White) {
+		t.Errorf("expected white at (21, 30), got %v", c)
+	}
+}
+
+func TestAnchorsAt(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	dot := []image.Image{solidMap(11, 11, red)}
+	pins := []onmap.Pin{
+		{Coord: onmap.Coord{0, 0}, Parts: dot, Anchors: onmap.AnchorsAt(dot, 0.5, 0.5)},
+		{Coord: onmap.Coord{0, 90}}, // default parts and anchors
+	}
+	m := onmap.RenderStyled(worldMap, pins, []image.Image{solidMap(10, 10, blue)}, nil)
+
+	// The dot is centered on the point.
+	p := image.Point{180, 180}
+	want := image.Rect(-5, -5, 6, 6).Add(p)
+	for y := want.Min.Y - 1; y <= want.Max.Y; y++ {
+		for x := want.Min.X - 1; x <= want.Max.X; x++ {
+			in := image.Pt(x, y).In(want)
+			if c := m.At(x, y); sameColor(c, red) != in {
+				t.Fatalf("at %v: expected dot %v, got %v", image.Pt(x, y), in, c)
+			}
+		}
+	}
+	// The default pin is at the bottom center.
+	q := image.Point{270, 180}
+	if c := m.At(q.X, q.Y-1); !sameColor(c, blue) {
+		t.Errorf("expected default pin above the point, got %v", c)
+	}
+	if c := m.At(q.X, q.Y); !sameColor(c, color.White) {
+		t.Errorf("expected no default pin below the point, got %v", c)
+	}
+}
+
+func TestRenderTransparentCorner(t *testing.T) {
+	// Opaque map with a transparent top-left corner.
+	worldMap := image.NewNRGBA(image.Rect(0, 0, 360, 360))
+	draw.Draw(worldMap, worldMap.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(worldMap, image.Rect(0, 0, 100, 100), image.Transparent, image.Point{}, draw.Src)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	pin := []image.Image{solidMap(10, 10, red)}
+	coords := []onmap.Coord{{0, 0}}
+
+	for _, opt := range []*onmap.Options{
+		nil,
+		{Crop: &onmap.CropOption{MinWidth: 360, MinHeight: 300}},
+		{Canvas: &onmap.CanvasOption{Width: 720, Height: 720}},
+	} {
+		m := onmap.Render(worldMap, pin, coords, opt)
+		b := m.Bounds()
+		if _, _, _, a := m.At(b.Min.X, b.Min.Y).RGBA(); a != 0 {
+			t.Errorf("expected transparent corner, got alpha %d", a)
+		}
+		if _, _, _, a := m.At(b.Max.X-1, b.Max.Y-1).RGBA(); a != 0xffff {
+			t.Errorf("expected opaque map, got alpha %d", a)
+		}
+		if countColor(m, red) == 0 {
+			t.Errorf("expected pin to be drawn")
+		}
+	}
+
+	// Pins drawn over the transparent corner are opaque.
+	m := onmap.Render(worldMap, pin, []onmap.Coord{{70, -150}}, nil)
+	p := onmap.Mercator.Convert(onmap.Coord{70, -150}, 360, 360)
+	if c := m.At(p.X, p.Y-5); !sameColor(c, red) {
+		t.Errorf("expected opaque pin over the transparent corner, got %v", c)
+	}
+	if _, _, _, a := m.At(p.X, p.Y+5).RGBA(); a != 0 {
+		t.Errorf("expected transparent map below the pin, got alpha %d", a)
+	}
+}
+
+func TestRenderOffMapPins(t *testing.T) {
+	worldMap := solidMap(400, 300, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	pin := []image.Image{solidMap(10, 10, red)}
+	// Regional map of Europe with pins elsewhere.
+	proj := onmap.Extent(onmap.Mercator, onmap.Coord{70, -10}, onmap.Coord{35, 40})
+	coords := []onmap.Coord{{-33.9, 151.2}, {40.7, -74.0}, {-60, 40}}
+	for _, opt := range []*onmap.Options{
+		{Projection: proj},
+		{Projection: proj, Cluster: &onmap.ClusterOption{Radius: 10}},
+	} {
+		m := onmap.Render(worldMap, pin, coords, opt)
+		if n := countColor(m, red); n != 0 {
+			t.Errorf("expected no pins, got %d pixels", n)
+		}
+	}
+}
+
+func BenchmarkRenderOffMapPins(b *testing.B) {
+	worldMap := solidMap(400, 300, color.White)
+	proj := onmap.Extent(onmap.Mercator, onmap.Coord{70, -10}, onmap.Coord{35, 40})
+	opt := &onmap.Options{Projection: proj}
+	b.Run("None", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			onmap.Render(worldMap, onmap.DefaultPin(), nil, opt)
+		}
+	})
+	b.Run("OffMap", func(b *testing.B) {
+		coords := randomCoords(10000, onmap.Bounds{SW: onmap.Coord{-60, 60}, NE: onmap.Coord{30, 180}})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			onmap.Render(worldMap, onmap.DefaultPin(), coords, opt)
+		}
+	})
+}
+
+// patternMap is a large world map that computes its pixels
+// instead of storing them.
+type patternMap struct {
+	r image.Rectangle
+}
+
+func (m patternMap) ColorModel() color.Model { return color.RGBAModel }
+
+func (m patternMap) Bounds() image.Rectangle { return m.r }
+
+func (m patternMap) At(x, y int) color.Color {
+	return patternColors[uint8(x^y)]
+}
+
+// patternColors are preallocated, so that At doesn't allocate.
+var patternColors = func() (colors [256]color.Color) {
+	for i := range colors {
+		colors[i] = color.RGBA{uint8(i), uint8(255 - i), 0x80, 0xff}
+	}
+	return
+}()
+
+var largeMap = patternMap{image.Rect(0, 0, 16384, 8192)}
+
+var largeMapCoords = []onmap.Coord{
+	{42.1, 19.1},             // Bar
+	{42.441286, 19.262892},   // Podgorica
+	{41.9097306, 12.2558141}, // Rome
+}
+
+func TestRenderLargeMapMemory(t *testing.T) {
+	pin := onmap.DefaultPin()
+	tests := []struct {
+		name string
+		opt  *onmap.Options
+		// factor is the maximum allocated memory relative to the crop.
+		factor uint64
+	}{
+		{"plain", &onmap.Options{Crop: onmap.StandardCrop}, 2},
+		// Resampling and rotating need source pixels and intermediate
+		// buffers for the crop, but not for the whole map.
+		{"canvas", &onmap.Options{Crop: onmap.StandardCrop, Canvas: &onmap.CanvasOption{Width: 12000, Height: 12000}}, 5},
+		{"scale", &onmap.Options{Crop: onmap.StandardCrop, Scale: 2}, 5},
+		{"rotation", &onmap.Options{Crop: onmap.StandardCrop, Rotation: &onmap.RotationOption{Bearing: 30, Center: largeMapCoords[0]}}, 5},
+	}
+	for _, tt := range tests {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		m := onmap.Render(largeMap, pin, largeMapCoords, tt.opt)
+		runtime.ReadMemStats(&after)
+
+		// Only the crop is drawn, not the whole map.
+		r := m.Bounds()
+		cropSize := uint64(r.Dx() * r.Dy() * 4)
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > tt.factor*cropSize {
+			t.Errorf("%s: allocated %d bytes for %v crop (%d bytes)", tt.name, allocated, r.Size(), cropSize)
+		}
+		if tt.name == "plain" {
+			if c := m.At(r.Min.X, r.Min.Y); !sameColor(c, largeMap.At(r.Min.X, r.Min.Y)) {
+				t.Errorf("expected map pixel %v at %v, got %v", largeMap.At(r.Min.X, r.Min.Y), r.Min, c)
+			}
+		}
+	}
+}
+
+func BenchmarkRenderLargeMap(b *testing.B) {
+	opt := &onmap.Options{Crop: onmap.StandardCrop}
+	b.Run("Crop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			onmap.Render(largeMap, onmap.DefaultPin(), largeMapCoords, opt)
+		}
+	})
+	b.Run("FullCanvas", func(b *testing.B) {
+		// Draw the whole map and pins, and then crop.
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			full := onmap.Render(largeMap, onmap.DefaultPin(), largeMapCoords, nil)
+			r := onmap.CropRect(onmap.Mercator, largeMap.r.Dx(), largeMap.r.Dy(), largeMapCoords, opt.Crop)
+			full.(*image.RGBA).SubImage(r)
+		}
+	})
+}
+
+func TestRenderFunctionsOptions(t *testing.T) {
+	worldMap := solidMap(720, 720, color.White)
+	a, b := onmap.Coord{10, -20}, onmap.Coord{-10, 30}
+	coords := []onmap.Coord{a, b}
+	last := func(frames []image.Image) image.Image { return frames[len(frames)-1] }
+	funcs := map[string]func(opt *onmap.Options) image.Image{
+		"MapArcs": func(opt *onmap.Options) image.Image {
+			return onmap.MapArcs(worldMap, onmap.DefaultPin(), [][2]onmap.Coord{{a, b}}, nil, opt)
+		},
+		"MapRoute": func(opt *onmap.Options) image.Image {
+			return onmap.MapRoute(worldMap, onmap.DefaultPin(), coords, nil, opt)
+		},
+		"MapAccuracy": func(opt *onmap.Options) image.Image {
+			return onmap.MapAccuracy(worldMap, onmap.DefaultPin(), a, 500000, nil, opt)
+		},
+		"MapDiff": func(opt *onmap.Options) image.Image {
+			return onmap.MapDiff(worldMap, coords[:1], coords, 3, opt)
+		},
+		"MapGlyphs": func(opt *onmap.Options) image.Image {
+			return onmap.MapGlyphs(worldMap, []onmap.GlyphMarker{{a, "A"}, {b, "B"}}, nil, opt)
+		},
+		"MapMarginLabels": func(opt *onmap.Options) image.Image {
+			return onmap.MapMarginLabels(worldMap, onmap.DefaultPin(), coords, []string{"a", "b"},
+				&onmap.MarginLabelOption{Width: 40}, opt)
+		},
+		"MapPinsLabeled": func(opt *onmap.Options) image.Image {
+			return onmap.MapPinsLabeled(worldMap, onmap.DefaultPin(), coords, []string{"a", "b"}, nil, opt)
+		},
+		"MapNumberedPins": func(opt *onmap.Options) image.Image {
+			return onmap.MapNumberedPins(worldMap, onmap.DefaultPin(), coords, nil, opt)
+		},
+		"MapVoronoi": func(opt *onmap.Options) image.Image {
+			return onmap.MapVoronoi(worldMap, onmap.DefaultPin(), coords, nil, opt)
+		},
+		"AnimatePins": func(opt *onmap.Options) image.Image {
+			return last(onmap.AnimatePins(worldMap, onmap.DefaultPin(),
+				[]onmap.AnimatedPin{{a, 0}, {b, 1}}, 2, nil, opt))
+		},
+		"AnimateTrail": func(opt *onmap.Options) image.Image {
+			return last(onmap.AnimateTrail(worldMap, onmap.DefaultPin(), coords, 3, 2, nil, opt))
+		},
+	}
+	crop := &onmap.CropOption{Bound: 20, MinWidth: 200, MinHeight: 200}
+	legend := &onmap.LegendOverlay{Entries: []onmap.LegendEntry{{Color: color.Black, Label: "x"}}}
+	for name, f := range funcs {
+		m1 := f(&onmap.Options{Crop: crop})
+		m2 := f(&onmap.Options{Crop: crop, Scale: 2})
+		if s1, s2 := m1.Bounds().Size(), m2.Bounds().Size(); s2 != s1.Mul(2) {
+			t.Errorf("%s: expected scaled size %v, got %v", name, s1.Mul(2), s2)
+		}
+		rotated := f(&onmap.Options{Crop: crop, Rotation: &onmap.RotationOption{Bearing: 90}})
+		if sameImage(m1, rotated) {
+			t.Errorf("%s: expected rotation to change the image", name)
+		}
+		withLegend := f(&onmap.Options{Crop: crop, Legend: legend})
+		if sameImage(m1, withLegend) {
+			t.Errorf("%s: expected legend to be drawn", name)
+		}
+	}
+}
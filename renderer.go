@@ -0,0 +1,72 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"sync"
+)
+
+// Renderer renders pins on a world map, reusing image buffers
+// released by the caller to reduce allocations when rendering
+// many maps. It is safe for concurrent use.
+type Renderer struct {
+	proj     Projection
+	worldMap *image.RGBA
+	pinParts []image.Image
+	pool     sync.Pool // of []uint8
+}
+
+// NewRenderer returns a new renderer of the given pin parts on the
+// world map in the given projection. The world map is converted to
+// RGBA once, so that it's not converted during each rendering.
+//
+// See MapPinsProjection for the description of pin parts.
+func NewRenderer(proj Projection, worldMap image.Image, pinParts []image.Image) *Renderer {
+	return &Renderer{
+		proj:     proj,
+		worldMap: newCanvas(worldMap),
+		pinParts: pinParts,
+	}
+}
+
+// Render returns an image with the given coordinates marked as pins
+// on the world map. If crop is nil, doesn't crop the image.
+//
+// The image is drawn directly into a buffer of the crop size, reusing
+// a buffer of an image passed to Release, if there's one large enough.
+// The returned image is owned by the caller.
+func (rd *Renderer) Render(coords []Coord, crop *CropOption) image.Image {
+	opt := &Options{Projection: rd.proj, Crop: crop}
+	m, _, _ := renderScene(context.Background(), rd.worldMap, opt, &scene{
+		coords: coords,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: rd.pinParts}
+		},
+		newImage: rd.newImage,
+	})
+	return m
+}
+
+// Release returns the image returned by Render to the renderer
+// to reuse its memory in later calls. The image must not be used
+// after calling Release.
+func (rd *Renderer) Release(m image.Image) {
+	if rgba, ok := m.(*image.RGBA); ok && rgba.Pix != nil {
+		rd.pool.Put(rgba.Pix[:0])
+	}
+}
+
+// newImage returns a transparent image with the given bounds
+// using a pooled buffer if it's large enough.
+func (rd *Renderer) newImage(r image.Rectangle) *image.RGBA {
+	n := r.Dx() * r.Dy() * 4
+	buf, _ := rd.pool.Get().([]uint8)
+	if cap(buf) < n {
+		return image.NewRGBA(r)
+	}
+	pix := buf[:n]
+	for i := range pix {
+		pix[i] = 0
+	}
+	return &image.RGBA{Pix: pix, Stride: r.Dx() * 4, Rect: r}
+}
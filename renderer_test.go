@@ -0,0 +1,71 @@
+package onmap_test
+
+import (
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderer(t *testing.T) {
+	rd := onmap.NewRenderer(onmap.Mercator, onmap.DefaultMap(), onmap.DefaultPin())
+	sets := [][]onmap.Coord{
+		{{51.5, -0.1}, {48.9, 2.4}},
+		{{-33.9, 151.2}},
+		{{40.7, -74}, {34, -118.2}, {41.9, -87.6}},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, coords := range sets {
+			wg.Add(1)
+			go func(coords []onmap.Coord) {
+				defer wg.Done()
+				got := rd.Render(coords, onmap.StandardCrop)
+				want := onmap.Pins(coords, onmap.StandardCrop)
+				if !sameImage(got, want) {
+					t.Errorf("%v: renderer image differs from Pins", coords)
+				}
+				// Reuse the buffer in later renderings.
+				rd.Release(got)
+			}(coords)
+		}
+	}
+	wg.Wait()
+}
+
+func sameImage(a, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if !sameColor(a.At(x, y), b.At(x, y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func BenchmarkRenderer(b *testing.B) {
+	coords := []onmap.Coord{{51.5, -0.1}, {48.9, 2.4}, {52.5, 13.4}}
+	b.Run("Pins", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				onmap.Pins(coords, onmap.StandardCrop)
+			}
+		})
+	})
+	b.Run("Renderer", func(b *testing.B) {
+		rd := onmap.NewRenderer(onmap.Mercator, onmap.DefaultMap(), onmap.DefaultPin())
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				rd.Release(rd.Render(coords, onmap.StandardCrop))
+			}
+		})
+	})
+}
@@ -0,0 +1,119 @@
+package onmap
+
+import (
+	"image"
+	"math"
+)
+
+// RotationOption defines the rotation of the map.
+type RotationOption struct {
+	// Bearing is the direction in degrees clockwise from north
+	// that points up on the rotated map.
+	Bearing float64
+
+	// Center is the coordinate the map is rotated about.
+	Center Coord
+}
+
+// rotation returns the rotation of the map or nil if it's not rotated.
+func (o *Options) rotation() *RotationOption {
+	if o == nil || o.Rotation == nil || math.Mod(o.Rotation.Bearing, 360) == 0 {
+		return nil
+	}
+	return o.Rotation
+}
+
+// rotator rotates points on the canvas about the center.
+type rotator struct {
+	cx, cy   float64
+	sin, cos float64
+}
+
+func (ro *RotationOption) rotator(l layout) rotator {
+	cx, cy := pixelCenter(l.point(ro.Center))
+	// Rotate counterclockwise by the bearing to point it up.
+	a := -ro.Bearing * math.Pi / 180
+	return rotator{cx: cx, cy: cy, sin: math.Sin(a), cos: math.Cos(a)}
+}
+
+// rotate returns the point rotated about the center
+// by the angle of the rotator, or by the opposite angle
+// if inverse is true.
+func (rt rotator) rotate(x, y float64, inverse bool) (float64, float64) {
+	sin := rt.sin
+	if inverse {
+		sin = -sin
+	}
+	dx, dy := x-rt.cx, y-rt.cy
+	return rt.cx + dx*rt.cos - dy*sin, rt.cy + dx*sin + dy*rt.cos
+}
+
+// points returns the points, as centers of their pixels,
+// rotated about the center.
+func (rt rotator) points(cs []image.Point) []image.Point {
+	out := make([]image.Point, len(cs))
+	for i, c := range cs {
+		x, y := pixelCenter(c)
+		x, y = rt.rotate(x, y, false)
+		out[i] = image.Point{int(math.Floor(x)), int(math.Floor(y))}
+	}
+	return out
+}
+
+// sourceRect returns the rectangle of the source image containing
+// all pixels that draw samples to draw the rectangle r.
+func (rt rotator) sourceRect(r image.Rectangle) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range []image.Point{r.Min, {r.Max.X, r.Min.Y}, {r.Min.X, r.Max.Y}, r.Max} {
+		x, y := rt.rotate(float64(p.X), float64(p.Y), true)
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	// Include neighbors sampled by bilinear interpolation.
+	return image.Rect(int(math.Floor(minX))-1, int(math.Floor(minY))-1,
+		int(math.Ceil(maxX))+2, int(math.Ceil(maxY))+2)
+}
+
+// draw draws the part of the rotated source image inside the bounds
+// of dst using bilinear interpolation. Pixels outside of the source
+// image are left unchanged.
+func (rt rotator) draw(dst, src *image.RGBA) {
+	r := dst.Bounds()
+	sb := src.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			// Sample at pixel centers.
+			fx, fy := rt.rotate(float64(x)+0.5, float64(y)+0.5, true)
+			fx, fy = fx-0.5, fy-0.5
+			x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+			if x0 < sb.Min.X-1 || y0 < sb.Min.Y-1 || x0 >= sb.Max.X || y0 >= sb.Max.Y {
+				continue
+			}
+			tx, ty := fx-float64(x0), fy-float64(y0)
+			var c [4]float64
+			for _, s := range [4]struct {
+				x, y int
+				w    float64
+			}{
+				{x0, y0, (1 - tx) * (1 - ty)},
+				{x0 + 1, y0, tx * (1 - ty)},
+				{x0, y0 + 1, (1 - tx) * ty},
+				{x0 + 1, y0 + 1, tx * ty},
+			} {
+				if !(image.Point{s.x, s.y}.In(sb)) {
+					// Transparent outside of the source.
+					continue
+				}
+				p := src.Pix[src.PixOffset(s.x, s.y):]
+				for k := range c {
+					c[k] += float64(p[k]) * s.w
+				}
+			}
+			d := dst.Pix[dst.PixOffset(x, y):]
+			for k := range c {
+				d[k] = uint8(math.Round(c[k]))
+			}
+		}
+	}
+}
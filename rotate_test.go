@@ -0,0 +1,52 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderRotation(t *testing.T) {
+	coords := []onmap.Coord{
+		{41.9097306, 12.2558141}, // Rome
+		{55.755833, 37.617222},   // Moscow
+	}
+	m := onmap.Render(onmap.DefaultMap(), onmap.DefaultPin(), coords, &onmap.Options{
+		Crop:     onmap.StandardCrop,
+		Rotation: &onmap.RotationOption{Bearing: 45, Center: coords[0]},
+	})
+	if err := writePng("test-rotation.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	pin := []image.Image{solidMap(10, 10, red)}
+	center := onmap.Coord{0, 0}
+	east := onmap.Coord{0, 45}
+	m = onmap.Render(worldMap, pin, []onmap.Coord{center, east}, &onmap.Options{
+		Rotation: &onmap.RotationOption{Bearing: 45, Center: center},
+	})
+	// With north-east up, the pin east of the center is to the right
+	// and above it, 45 pixels away.
+	p := image.Point{180 + 32, 180 - 32}
+	if c := m.At(p.X, p.Y-5); !sameColor(c, red) {
+		t.Errorf("expected rotated pin at %v, got %v", p, c)
+	}
+	// Pins are not rotated: each is an axis-aligned 10x10 square.
+	for _, q := range []image.Point{{180, 180}, p} {
+		pr := image.Rect(q.X-5, q.Y-10, q.X+5, q.Y)
+		if n := countColor(m.(*image.RGBA).SubImage(pr), red); n != 100 {
+			t.Errorf("expected axis-aligned pin at %v, got %d pixels", pr, n)
+		}
+	}
+	if n := countColor(m, red); n != 200 {
+		t.Errorf("expected 200 pin pixels, got %d", n)
+	}
+	// The corners of the canvas are not covered by the rotated map.
+	if _, _, _, a := m.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("expected transparent corner, got alpha %d", a)
+	}
+}
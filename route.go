@@ -0,0 +1,48 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+)
+
+// RouteOption defines options for MapRoute.
+type RouteOption struct {
+	// Color is a color of the route.
+	Color color.Color
+
+	// Width is a width of the route in pixels.
+	Width float64
+}
+
+// DefaultRoute is the default route option.
+var DefaultRoute = &RouteOption{
+	Color: color.RGBA{0x20, 0x60, 0xe0, 0xff},
+	Width: 3,
+}
+
+// MapRoute is like Render, but draws the route connecting the stops in
+// order with straight lines on the map and pins at the stops. The route
+// is drawn beneath pins. Segments crossing the antimeridian are split
+// at it, continuing from the other side of the map, instead of going
+// across the whole map.
+//
+// If route is nil, DefaultRoute is used. See Render for
+// the description of pin parts and options.
+func MapRoute(worldMap image.Image, pinParts []image.Image, stops []Coord, route *RouteOption, opt *Options) image.Image {
+	if route == nil {
+		route = DefaultRoute
+	}
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		coords: stops,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts}
+		},
+		onMap: func(m *image.RGBA, cs []image.Point, l layout) {
+			for _, path := range splitAntimeridian(stops) {
+				drawGeoLine(m, l, path, l.px(route.Width), route.Color)
+			}
+		},
+	})
+	return finish(m, r, opt)
+}
@@ -0,0 +1,50 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapRoute(t *testing.T) {
+	stops := []onmap.Coord{
+		{34.052235, -118.243683}, // Los Angeles
+		{36.169941, -115.139832}, // Las Vegas
+		{40.760780, -111.891045}, // Salt Lake City
+		{39.739235, -104.990250}, // Denver
+	}
+	m := onmap.MapRoute(onmap.DefaultMap(), onmap.DefaultPin(), stops, nil,
+		&onmap.Options{Crop: onmap.StandardCrop})
+	if err := writePng("test-route.png", m); err != nil {
+		t.Fatal(err)
+	}
+
+	worldMap := solidMap(720, 720, color.White)
+	routeColor := color.RGBA{0, 0, 0xff, 0xff}
+	route := &onmap.RouteOption{Color: routeColor, Width: 3}
+	m = onmap.MapRoute(worldMap, nil, stops, route, nil)
+	// The route passes between Las Vegas and Salt Lake City.
+	a := onmap.Mercator.Convert(stops[1], 720, 720)
+	b := onmap.Mercator.Convert(stops[2], 720, 720)
+	mid := image.Point{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+	if countColor(m.(*image.RGBA).SubImage(image.Rect(mid.X-2, mid.Y-2, mid.X+3, mid.Y+3)), routeColor) == 0 {
+		t.Errorf("expected route at %v", mid)
+	}
+
+	// Route across the Pacific is split at the antimeridian
+	// instead of crossing the whole map.
+	pacific := []onmap.Coord{
+		{35.6895, 139.6917},  // Tokyo
+		{21.3069, -157.8583}, // Honolulu
+	}
+	m = onmap.MapRoute(worldMap, nil, pacific, route, nil)
+	if n := countColor(m.(*image.RGBA).SubImage(image.Rect(355, 0, 365, 720)), routeColor); n != 0 {
+		t.Errorf("expected no route at the prime meridian, got %d pixels", n)
+	}
+	if countColor(m.(*image.RGBA).SubImage(image.Rect(0, 0, 5, 720)), routeColor) == 0 ||
+		countColor(m.(*image.RGBA).SubImage(image.Rect(715, 0, 720, 720)), routeColor) == 0 {
+		t.Errorf("expected route to reach both edges of the map")
+	}
+}
@@ -0,0 +1,254 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// scaleImage returns the image resized to the given dimensions.
+//
+// It uses a separable triangle filter whose support grows
+// with the downscaling factor, so it works as bilinear
+// interpolation when enlarging and as area averaging
+// when reducing images.
+func scaleImage(src image.Image, width, height int) *image.RGBA {
+	return scaleRegion(src, width, height, image.Rect(0, 0, width, height))
+}
+
+// scaleRegion is like scaleImage, but returns only the part of the
+// resized image inside r, reading only the source pixels it needs.
+func scaleRegion(src image.Image, width, height int, r image.Rectangle) *image.RGBA {
+	r = r.Intersect(image.Rect(0, 0, width, height))
+	dst := image.NewRGBA(r)
+	sb := src.Bounds()
+	if r.Empty() || sb.Empty() {
+		return dst
+	}
+	xw := filterWeights(sb.Dx(), width, r.Min.X, r.Max.X)
+	yw := filterWeights(sb.Dy(), height, r.Min.Y, r.Max.Y)
+
+	// Copy the source pixels used by the filter.
+	x0, x1 := weightsRange(xw)
+	y0, y1 := weightsRange(yw)
+	sr := image.Rect(x0, y0, x1, y1)
+	s, ok := src.(*image.RGBA)
+	if !ok || s.Bounds().Min != (image.Point{}) {
+		s = image.NewRGBA(sr)
+		draw.Draw(s, sr, src, sb.Min.Add(sr.Min), draw.Src)
+	}
+
+	// For each row, resample vertically into row (sr.Dx() pixels),
+	// then horizontally into dst, so that only a single row
+	// of intermediate values is kept.
+	row := make([]float64, sr.Dx()*4)
+	for y, ws := range yw {
+		for i := range row {
+			row[i] = 0
+		}
+		for _, fw := range ws {
+			p := s.Pix[s.PixOffset(sr.Min.X, fw.i):]
+			for i := range row {
+				row[i] += float64(p[i]) * fw.w
+			}
+		}
+		for x, ws := range xw {
+			var c [4]float64
+			for _, fw := range ws {
+				p := row[(fw.i-sr.Min.X)*4:]
+				for k := range c {
+					c[k] += p[k] * fw.w
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for k := range c {
+				d[k] = uint8(math.Max(0, math.Min(255, math.Round(c[k]))))
+			}
+		}
+	}
+	return dst
+}
+
+type filterWeight struct {
+	i int
+	w float64
+}
+
+// filterWeights returns normalized weights of source pixels for each
+// destination pixel from min to max (exclusive) when resizing
+// from n to m pixels.
+func filterWeights(n, m, min, max int) [][]filterWeight {
+	scale := float64(n) / float64(m)
+	support := math.Max(1, scale)
+	weights := make([][]filterWeight, max-min)
+	for jj := range weights {
+		j := min + jj
+		center := (float64(j)+0.5)*scale - 0.5
+		var ws []filterWeight
+		sum := 0.0
+		for i := int(math.Ceil(center - support)); i <= int(math.Floor(center+support)); i++ {
+			w := 1 - math.Abs(float64(i)-center)/support
+			if w <= 0 {
+				continue
+			}
+			k := i
+			if k < 0 {
+				k = 0
+			}
+			if k >= n {
+				k = n - 1
+			}
+			ws = append(ws, filterWeight{k, w})
+			sum += w
+		}
+		for i := range ws {
+			ws[i].w /= sum
+		}
+		weights[jj] = ws
+	}
+	return weights
+}
+
+// weightsRange returns the range of source pixels used by the weights.
+func weightsRange(weights [][]filterWeight) (min, max int) {
+	first, last := weights[0], weights[len(weights)-1]
+	return first[0].i, last[len(last)-1].i + 1
+}
+
+// scaledImage is the image resized to the given dimensions on demand:
+// drawMap resizes only the parts it draws, so that the whole resized
+// image is never allocated.
+type scaledImage struct {
+	src           image.Image
+	width, height int
+}
+
+func (m *scaledImage) ColorModel() color.Model { return color.RGBAModel }
+
+func (m *scaledImage) Bounds() image.Rectangle { return image.Rect(0, 0, m.width, m.height) }
+
+func (m *scaledImage) At(x, y int) color.Color {
+	return scaleRegion(m.src, m.width, m.height, image.Rect(x, y, x+1, y+1)).At(x, y)
+}
+
+// region returns an image with the part of src resized to the given
+// dimensions inside r, and the point of the returned image that
+// corresponds to the top-left corner of the resized image.
+// Images resized by scaledImage are resized from their source once.
+func region(src image.Image, width, height int, r image.Rectangle) (image.Image, image.Point) {
+	if s, ok := src.(*scaledImage); ok {
+		src = s.src
+	} else if src.Bounds().Dx() == width && src.Bounds().Dy() == height {
+		return src, src.Bounds().Min
+	}
+	return scaleRegion(src, width, height, r), image.Point{}
+}
+
+// scaleBy returns the image scaled by the given factor.
+func scaleBy(src image.Image, factor float64) *image.RGBA {
+	b := src.Bounds()
+	w := int(math.Round(float64(b.Dx()) * factor))
+	h := int(math.Round(float64(b.Dy()) * factor))
+	return scaleImage(src, w, h)
+}
+
+// scaleRender returns the world map, options, and the marker maker
+// scaled according to opt.Scale. Returned options have no Scale.
+func scaleRender(worldMap image.Image, opt *Options, mk func(i int, pt image.Point) marker) (image.Image, *Options, func(i int, pt image.Point) marker) {
+	if opt == nil || opt.Scale == 0 || opt.Scale == 1 {
+		return worldMap, opt, mk
+	}
+	s := opt.Scale
+	o := *opt
+	o.Scale = 0
+	if c := opt.Crop; c != nil {
+		crop := *c
+		crop.Bound = scaleInt(c.Bound, s)
+		crop.BoundTop = scaleInt(c.BoundTop, s)
+		crop.BoundBottom = scaleInt(c.BoundBottom, s)
+		crop.BoundLeft = scaleInt(c.BoundLeft, s)
+		crop.BoundRight = scaleInt(c.BoundRight, s)
+		crop.MinWidth = scaleInt(c.MinWidth, s)
+		crop.MinHeight = scaleInt(c.MinHeight, s)
+		crop.MaxWidth = scaleInt(c.MaxWidth, s)
+		crop.MaxHeight = scaleInt(c.MaxHeight, s)
+		crop.MaxZoomPixelsPerDegree = c.MaxZoomPixelsPerDegree * s
+		o.Crop = &crop
+	}
+	if c := opt.Canvas; c != nil {
+		canvas := *c
+		canvas.Width = scaleInt(c.Width, s)
+		canvas.Height = scaleInt(c.Height, s)
+		o.Canvas = &canvas
+	}
+	if c := opt.Cluster; c != nil && c.DisplayWidth == 0 {
+		// With DisplayWidth, the radius is already relative to the crop.
+		cluster := *c
+		cluster.Radius = scaleInt(c.Radius, s)
+		o.Cluster = &cluster
+	}
+	if g := opt.Graticule; g != nil {
+		graticule := *g
+		graticule.Width = g.width() * s
+		o.Graticule = &graticule
+	}
+	if lo := opt.Legend; lo != nil {
+		legend := *lo
+		legend.Margin = scaleInt(lo.margin(), s)
+		o.Legend = &legend
+	}
+	o.Anchors = scaleAnchors(opt.Anchors, s)
+
+	b := worldMap.Bounds()
+	scaled := &scaledImage{worldMap, scaleInt(b.Dx(), s), scaleInt(b.Dy(), s)}
+	if mk == nil {
+		return scaled, &o, nil
+	}
+
+	// Scale each set of pin parts once.
+	type partsKey struct {
+		first *image.Image
+		n     int
+	}
+	cache := make(map[partsKey][]image.Image)
+	smk := func(i int, pt image.Point) marker {
+		m := mk(i, pt)
+		if len(m.parts) == 0 {
+			return m
+		}
+		k := partsKey{&m.parts[0], len(m.parts)}
+		parts, ok := cache[k]
+		if !ok {
+			parts = make([]image.Image, len(m.parts))
+			for j, p := range m.parts {
+				parts[j] = scaleBy(p, s)
+			}
+			cache[k] = parts
+		}
+		m.parts = parts
+		m.anchors = scaleAnchors(m.anchors, s)
+		return m
+	}
+	return scaled, &o, smk
+}
+
+// scaleAnchors returns anchors scaled by s.
+func scaleAnchors(anchors []Anchor, s float64) []Anchor {
+	if anchors == nil {
+		return nil
+	}
+	scaled := make([]Anchor, len(anchors))
+	for i, a := range anchors {
+		scaled[i] = Anchor{
+			Tip:    image.Point{scaleInt(a.Tip.X, s), scaleInt(a.Tip.Y, s)},
+			Offset: image.Point{scaleInt(a.Offset.X, s), scaleInt(a.Offset.Y, s)},
+		}
+	}
+	return scaled
+}
+
+// scaleInt returns v multiplied by s rounded to the nearest integer.
+func scaleInt(v int, s float64) int {
+	return int(math.Round(float64(v) * s))
+}
@@ -0,0 +1,170 @@
+package onmap
+
+import (
+	"context"
+	"image"
+)
+
+// scene defines what renderScene draws on the world map.
+type scene struct {
+	// coords are coordinates of markers made by mk.
+	// If mk is nil, no markers are drawn.
+	coords []Coord
+	mk     func(i int, pt image.Point) marker
+
+	// extra are coordinates of other points to include in the crop,
+	// such as points of lines.
+	extra []Coord
+
+	// cropPoints, if not nil, returns points to compute the crop from
+	// given points of coords followed by extra on the image.
+	cropPoints func(cs []image.Point) []image.Point
+
+	// onMap, if not nil, draws on the map beneath pins given points
+	// of coords followed by extra on the canvas, so that the drawing
+	// is rotated with the map.
+	onMap func(m *image.RGBA, cs []image.Point, l layout)
+
+	// beneath and above, if not nil, draw on the image beneath and
+	// above pins given points of coords followed by extra on the image.
+	beneath, above func(m *image.RGBA, cs []image.Point, l layout)
+
+	// placed, if not nil, is called with the index of the coordinate and
+	// its marker as drawn, scaled and with anchors from options if it has
+	// none, before markers are culled to the crop.
+	placed func(i int, m marker)
+
+	// layers are layers of the scene to draw. If zero, all are drawn.
+	layers sceneLayer
+
+	// newImage, if not nil, returns a transparent image
+	// with the given bounds to draw into.
+	newImage func(r image.Rectangle) *image.RGBA
+}
+
+// sceneLayer is a set of layers of the scene.
+type sceneLayer int
+
+const (
+	sceneMap     sceneLayer = 1 << iota // world map and canvas background
+	sceneLines                          // graticule and drawings beneath pins
+	sceneShadows                        // first pin parts
+	scenePins                           // other pin parts
+	sceneLabels                         // badges, drawings above pins, and legend
+
+	sceneAll = sceneMap | sceneLines | sceneShadows | scenePins | sceneLabels
+)
+
+// renderScene draws the scene on the world map according to options
+// and returns the resulting image and its crop rectangle. Only the crop
+// rectangle of the canvas is drawn. It stops and returns the context
+// error if the context is done.
+func renderScene(ctx context.Context, worldMap image.Image, opt *Options, sc *scene) (*image.RGBA, image.Rectangle, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, image.Rectangle{}, err
+	}
+	layers := sc.layers
+	if layers == 0 {
+		layers = sceneAll
+	}
+	var scale float64
+	if opt != nil {
+		scale = opt.Scale
+	}
+	mk := sc.mk
+	worldMap, opt, mk = scaleRender(worldMap, opt, mk)
+
+	coords := make([]Coord, 0, len(sc.coords)+len(sc.extra))
+	coords = append(append(coords, sc.coords...), sc.extra...)
+	cs, l := layoutPoints(worldMap, coords, opt)
+	l.scale = scale
+	mapPoints := cs
+	rot := opt.rotation()
+	var rt rotator
+	if rot != nil {
+		rt = rot.rotator(l)
+		cs = rt.points(cs)
+	}
+	var crop []image.Point
+	if sc.cropPoints != nil {
+		crop = sc.cropPoints(cs)
+	} else {
+		crop = l.visible(coords, cs)
+	}
+	r := opt.cropRect(crop, l)
+
+	var m *image.RGBA
+	if sc.newImage != nil {
+		m = sc.newImage(r)
+	} else {
+		m = image.NewRGBA(r)
+	}
+	if rot != nil {
+		// Draw only the part of the canvas rotated into the crop.
+		src := l.draw(worldMap, opt, rt.sourceRect(r).Intersect(l.canvas), layers)
+		if layers&sceneLines != 0 && sc.onMap != nil {
+			sc.onMap(src, mapPoints, l)
+		}
+		rt.draw(m, src)
+	} else {
+		l.drawMap(m, worldMap, opt, layers)
+		if layers&sceneLines != 0 && sc.onMap != nil {
+			sc.onMap(m, mapPoints, l)
+		}
+	}
+	if layers&sceneLines != 0 && sc.beneath != nil {
+		sc.beneath(m, cs, l)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, image.Rectangle{}, err
+	}
+
+	var badges []badge
+	if mk != nil {
+		n := len(sc.coords)
+		if opt != nil && opt.Subpixel && rot == nil {
+			mk = l.subpixelMarkers(coords, mk)
+		}
+		if sc.placed != nil {
+			mk = placedMarkers(mk, opt.anchors(), sc.placed)
+		}
+		var markers []marker
+		markers, badges = makeMarkers(cs[:n], r, opt, mk)
+		first, last := 0, -1
+		if layers&sceneShadows == 0 {
+			first = 1
+		}
+		if layers&scenePins == 0 {
+			last = 1
+		}
+		if first != 1 || last != 1 {
+			if err := drawMarkerParts(ctx, m, markers, opt, first, last); err != nil {
+				return nil, image.Rectangle{}, err
+			}
+		}
+	}
+	if layers&sceneLabels != 0 {
+		drawBadges(m, badges, opt.badgeColor())
+		if sc.above != nil {
+			sc.above(m, cs, l)
+		}
+		if opt != nil && opt.Legend != nil {
+			opt.Legend.draw(m, r)
+		}
+	}
+	return m, r, nil
+}
+
+// placedMarkers returns mk that also calls placed with each marker
+// it makes, setting the given anchors if the marker has none.
+func placedMarkers(mk func(i int, pt image.Point) marker, anchors []Anchor, placed func(i int, m marker)) func(i int, pt image.Point) marker {
+	return func(i int, pt image.Point) marker {
+		m := mk(i, pt)
+		pm := m
+		if pm.anchors == nil {
+			pm.anchors = anchors
+		}
+		placed(i, pm)
+		return m
+	}
+}
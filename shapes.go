@@ -0,0 +1,101 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// shapeMask is an anti-aliased alpha mask of a shape
+// described by a signed distance function: negative
+// distances are inside the shape, positive are outside.
+type shapeMask struct {
+	r    image.Rectangle
+	dist func(x, y float64) float64
+}
+
+func (m *shapeMask) ColorModel() color.Model { return color.AlphaModel }
+
+func (m *shapeMask) Bounds() image.Rectangle { return m.r }
+
+func (m *shapeMask) At(x, y int) color.Color {
+	// Sample at pixel center.
+	a := 0.5 - m.dist(float64(x)+0.5, float64(y)+0.5)
+	if a <= 0 {
+		return color.Alpha{0}
+	}
+	if a >= 1 {
+		return color.Alpha{0xff}
+	}
+	return color.Alpha{uint8(a * 0xff)}
+}
+
+// fillShape draws the shape mask filled with the given color onto dst.
+func fillShape(dst draw.Image, mask *shapeMask, c color.Color) {
+	r := mask.r.Intersect(dst.Bounds())
+	if r.Empty() {
+		return
+	}
+	draw.DrawMask(dst, r, image.NewUniform(c), image.Point{}, mask, r.Min, draw.Over)
+}
+
+// circleMask returns a mask of the circle with the given center and radius.
+func circleMask(cx, cy, radius float64) *shapeMask {
+	return &shapeMask{
+		r: image.Rect(
+			int(math.Floor(cx-radius-1)), int(math.Floor(cy-radius-1)),
+			int(math.Ceil(cx+radius+1)), int(math.Ceil(cy+radius+1)),
+		),
+		dist: func(x, y float64) float64 {
+			return math.Hypot(x-cx, y-cy) - radius
+		},
+	}
+}
+
+// lineMask returns a mask of the line segment from (x0, y0)
+// to (x1, y1) of the given width with round caps.
+func lineMask(x0, y0, x1, y1, width float64) *shapeMask {
+	hw := width / 2
+	dx, dy := x1-x0, y1-y0
+	l2 := dx*dx + dy*dy
+	return &shapeMask{
+		r: image.Rect(
+			int(math.Floor(math.Min(x0, x1)-hw-1)), int(math.Floor(math.Min(y0, y1)-hw-1)),
+			int(math.Ceil(math.Max(x0, x1)+hw+1)), int(math.Ceil(math.Max(y0, y1)+hw+1)),
+		),
+		dist: func(x, y float64) float64 {
+			t := 0.0
+			if l2 > 0 {
+				t = ((x-x0)*dx + (y-y0)*dy) / l2
+				t = math.Max(0, math.Min(1, t))
+			}
+			return math.Hypot(x-(x0+t*dx), y-(y0+t*dy)) - hw
+		},
+	}
+}
+
+// fillCircle draws a filled circle onto dst.
+func fillCircle(dst draw.Image, cx, cy, radius float64, c color.Color) {
+	fillShape(dst, circleMask(cx, cy, radius), c)
+}
+
+// strokeLine draws a line segment onto dst.
+func strokeLine(dst draw.Image, x0, y0, x1, y1, width float64, c color.Color) {
+	fillShape(dst, lineMask(x0, y0, x1, y1, width), c)
+}
+
+// strokePolyline draws line segments connecting centers
+// of pixels at points in order onto dst.
+func strokePolyline(dst draw.Image, points []image.Point, width float64, c color.Color) {
+	for i := 1; i < len(points); i++ {
+		x0, y0 := pixelCenter(points[i-1])
+		x1, y1 := pixelCenter(points[i])
+		strokeLine(dst, x0, y0, x1, y1, width, c)
+	}
+}
+
+// pixelCenter returns coordinates of the center of the pixel at p.
+func pixelCenter(p image.Point) (x, y float64) {
+	return float64(p.X) + 0.5, float64(p.Y) + 0.5
+}
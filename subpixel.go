@@ -0,0 +1,98 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// subpixelScale is the number of steps per pixel
+// in which subpixel positions are computed.
+const subpixelScale = 64
+
+// subpixel is the offset of a point in pixels.
+type subpixel struct {
+	x, y float64
+}
+
+// pointF converts the coordinate to a fractional point on the canvas.
+//
+// Projections return whole pixels, so the coordinate is converted
+// on a larger map and the point is scaled down.
+func (l layout) pointF(c Coord) (x, y float64) {
+	w, h := l.mapRect.Dx(), l.mapRect.Dy()
+	p := l.proj.Convert(c, w*subpixelScale, h*subpixelScale)
+	x, y = float64(p.X)/subpixelScale, float64(p.Y)/subpixelScale
+	if l.shift != 0 {
+		x = math.Mod(x+float64(l.shift), float64(w))
+		if x < 0 {
+			x += float64(w)
+		}
+	}
+	return x + float64(l.mapRect.Min.X), y + float64(l.mapRect.Min.Y)
+}
+
+// subpixel returns the offset of the fractional point
+// of the coordinate from its point pt on the canvas.
+func (l layout) subpixel(c Coord, pt image.Point) subpixel {
+	x, y := l.pointF(c)
+	return subpixel{x - float64(pt.X), y - float64(pt.Y)}
+}
+
+// subpixelMarkers returns the marker maker that sets subpixel offsets
+// of markers made by mk at points of the given coordinates.
+func (l layout) subpixelMarkers(coords []Coord, mk func(i int, pt image.Point) marker) func(i int, pt image.Point) marker {
+	return func(i int, pt image.Point) marker {
+		m := mk(i, pt)
+		if sub := l.subpixel(coords[i], pt); math.Abs(sub.x) <= 1 && math.Abs(sub.y) <= 1 {
+			// Not a marker at another point, such as a cluster.
+			m.sub = sub
+		}
+		return m
+	}
+}
+
+// drawnPart returns the i-th pin part of the marker and the
+// rectangle to draw it in, shifted by the subpixel offset.
+func (mk marker) drawnPart(i int) (image.Image, image.Rectangle) {
+	part, r := mk.parts[i], mk.partRect(i)
+	if mk.sub == (subpixel{}) {
+		return part, r
+	}
+	fx, fy := math.Floor(mk.sub.x), math.Floor(mk.sub.y)
+	r = r.Add(image.Point{int(fx), int(fy)})
+	shifted := shiftImage(part, mk.sub.x-fx, mk.sub.y-fy)
+	return shifted, image.Rectangle{r.Min, r.Min.Add(shifted.Rect.Size())}
+}
+
+// shiftImage returns the image moved right and down by the given
+// fractions of a pixel from 0 to 1 with bilinear interpolation.
+// The returned image is one pixel wider and taller than src.
+func shiftImage(src image.Image, dx, dy float64) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()+1, b.Dy()+1))
+	at := func(x, y int) color.RGBA64 {
+		if x < 0 || y < 0 || x >= b.Dx() || y >= b.Dy() {
+			return color.RGBA64{}
+		}
+		return color.RGBA64Model.Convert(src.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA64)
+	}
+	weights := [4]float64{(1 - dx) * (1 - dy), dx * (1 - dy), (1 - dx) * dy, dx * dy}
+	for y := 0; y <= b.Dy(); y++ {
+		for x := 0; x <= b.Dx(); x++ {
+			var c [4]float64
+			for k, p := range [4]color.RGBA64{at(x, y), at(x-1, y), at(x, y-1), at(x-1, y-1)} {
+				w := weights[k]
+				c[0] += float64(p.R) * w
+				c[1] += float64(p.G) * w
+				c[2] += float64(p.B) * w
+				c[3] += float64(p.A) * w
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for k := range c {
+				d[k] = uint8(math.Round(c[k] / 0x101))
+			}
+		}
+	}
+	return dst
+}
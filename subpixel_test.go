@@ -0,0 +1,38 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestRenderSubpixel(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	pin := []image.Image{solidMap(10, 10, red)}
+	// The point is at x = 180.3 on the map.
+	coords := []onmap.Coord{{0, 0.3}}
+	y := 175
+
+	// Integer placement rounds the point to 180,
+	// so the pin covers whole pixels from 175 to 184.
+	m := onmap.Render(worldMap, pin, coords, &onmap.Options{Projection: onmap.Equirectangular})
+	for x, want := range map[int]color.Color{174: color.White, 175: red, 184: red, 185: color.White} {
+		if c := m.At(x, y); !sameColor(c, want) {
+			t.Errorf("integer: expected %v at x = %d, got %v", want, x, c)
+		}
+	}
+
+	// Subpixel placement covers 70% of the left
+	// edge pixel and 30% of the right one.
+	m = onmap.Render(worldMap, pin, coords, &onmap.Options{Projection: onmap.Equirectangular, Subpixel: true})
+	for x, coverage := range map[int]float64{174: 0, 175: 0.7, 180: 1, 185: 0.3, 186: 0} {
+		_, g, _, _ := m.At(x, y).RGBA()
+		want := uint32((1 - coverage) * 0xffff)
+		if abs(int(g)-int(want)) > 0x200 {
+			t.Errorf("subpixel: expected %.0f%% coverage at x = %d, got %v", coverage*100, x, m.At(x, y))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package onmap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// MapPinsSVG writes an SVG image with the given coordinates marked as
+// default pins on the default map. If crop is nil, doesn't crop the image.
+//
+// The map is embedded as a JPEG image (or PNG, if it was replaced
+// with SetDefaultAssets), and each pin part is embedded once
+// as a PNG image and placed at the points of pins with <use> elements,
+// so pins stay sharp when the SVG is scaled. The crop is applied by
+// setting the viewBox to the crop rectangle, so the whole map is
+// embedded regardless of it. If the map is rolled with
+// CropOption.WrapAntimeridian, it's placed twice to wrap around.
+func MapPinsSVG(w io.Writer, coords []Coord, crop *CropOption) error {
+	worldMap, custom, err := loadMapCustom()
+	if err != nil {
+		return err
+	}
+	pinParts, err := loadPin()
+	if err != nil {
+		return err
+	}
+	opt := &Options{Crop: crop}
+	cs, l := layoutPoints(worldMap, coords, opt)
+	r := opt.cropRect(l.visible(coords, cs), l)
+	markers, _ := makeMarkers(cs, r, opt, func(i int, pt image.Point) marker {
+		return marker{pt: pt, parts: pinParts}
+	})
+	markers = sortMarkers(markers, opt)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="%d %d %d %d">`+"\n",
+		r.Dx(), r.Dy(), r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	b := worldMap.Bounds()
+	mapType, mapData := "jpeg", mercatorData
+	if custom {
+		var p bytes.Buffer
+		if err := png.Encode(&p, worldMap); err != nil {
+			return err
+		}
+		mapType, mapData = "png", p.Bytes()
+	}
+	// Roll the map as when rendering images, placing it again
+	// to the left to wrap around, see CropOption.WrapAntimeridian.
+	shift := mod(l.shift, b.Dx())
+	fmt.Fprintf(&buf, `<image id="map" x="%d" width="%d" height="%d" xlink:href="data:image/%s;base64,%s"/>`+"\n",
+		shift, b.Dx(), b.Dy(), mapType, base64.StdEncoding.EncodeToString(mapData))
+	if shift != 0 {
+		fmt.Fprintf(&buf, `<use xlink:href="#map" x="%d"/>`+"\n", -b.Dx())
+	}
+	buf.WriteString("<defs>\n")
+	for i, part := range pinParts {
+		var p bytes.Buffer
+		if err := png.Encode(&p, part); err != nil {
+			return err
+		}
+		pb := part.Bounds()
+		fmt.Fprintf(&buf, `<image id="pin-part-%d" width="%d" height="%d" xlink:href="data:image/png;base64,%s"/>`+"\n",
+			i, pb.Dx(), pb.Dy(), base64.StdEncoding.EncodeToString(p.Bytes()))
+	}
+	buf.WriteString("</defs>\n")
+	// Draw pin parts of the same index for all pins together,
+	// as when rendering images, so shadows are below pins.
+	for i := range pinParts {
+		fmt.Fprintf(&buf, `<g class="pin-part-%d">`+"\n", i)
+		for _, mk := range markers {
+			pr := mk.partRect(i)
+			fmt.Fprintf(&buf, `<use class="pin" xlink:href="#pin-part-%d" x="%d" y="%d"/>`+"\n", i, pr.Min.X, pr.Min.Y)
+		}
+		buf.WriteString("</g>\n")
+	}
+	buf.WriteString("</svg>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,59 @@
+package onmap_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapPinsSVG(t *testing.T) {
+	coords := []onmap.Coord{
+		{51.5, -0.1}, // London
+		{48.9, 2.4},  // Paris
+		{52.5, 13.4}, // Berlin
+	}
+	var buf bytes.Buffer
+	if err := onmap.MapPinsSVG(&buf, coords, onmap.StandardCrop); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	parts := len(onmap.DefaultPin())
+	if n := strings.Count(s, `<use class="pin"`); n != len(coords)*parts {
+		t.Errorf("expected %d pin elements, got %d", len(coords)*parts, n)
+	}
+	r := onmap.Pins(coords, onmap.StandardCrop).Bounds()
+	viewBox := fmt.Sprintf(`viewBox="%d %d %d %d"`, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	if !strings.Contains(s, viewBox) {
+		t.Errorf("expected %s", viewBox)
+	}
+}
+
+func TestMapPinsSVGWrap(t *testing.T) {
+	coords := []onmap.Coord{{-17, 178}, {-14, -171}}
+	crop := &onmap.CropOption{Bound: 20, WrapAntimeridian: true}
+	var buf bytes.Buffer
+	if err := onmap.MapPinsSVG(&buf, coords, crop); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	w := onmap.DefaultMap().Bounds().Dx()
+	res := onmap.MapPinsResult(onmap.Mercator, onmap.DefaultMap(), nil, coords, crop)
+	if res.Shift == 0 {
+		t.Fatalf("expected rolled map")
+	}
+	r := res.CropRect
+	viewBox := fmt.Sprintf(`viewBox="%d %d %d %d"`, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	if !strings.Contains(s, viewBox) {
+		t.Errorf("expected %s", viewBox)
+	}
+	// The map is placed rolled and again to the left of it.
+	if image := fmt.Sprintf(`<image id="map" x="%d"`, res.Shift); !strings.Contains(s, image) {
+		t.Errorf("expected %s", image)
+	}
+	if use := fmt.Sprintf(`<use xlink:href="#map" x="%d"/>`, -w); !strings.Contains(s, use) {
+		t.Errorf("expected %s", use)
+	}
+}
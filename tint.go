@@ -0,0 +1,93 @@
+package onmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// TintPin returns a copy of the pin image recolored with the given color,
+// preserving its transparency and shading, for use in pin parts.
+// The most common opaque color of the image, such as the color of
+// the pin head, becomes the given color.
+//
+// To keep the shadow untouched, tint only the pin body:
+//
+//	parts := onmap.DefaultPin()
+//	red := []image.Image{parts[0], onmap.TintPin(parts[1], color.RGBA{0xff, 0, 0, 0xff})}
+func TintPin(src image.Image, c color.Color) image.Image {
+	return colorize(src, c)
+}
+
+// colorize returns a copy of the image recolored with the given color,
+// preserving alpha and shading: pixels of the most common opaque color
+// of the image become the given color, darker pixels are blended
+// towards black, lighter ones towards white.
+func colorize(src image.Image, c color.Color) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+
+	// Find lightness of the most common opaque color.
+	counts := make(map[color.NRGBA]int)
+	var base color.NRGBA
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			p := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			if p.A != 0xff {
+				continue
+			}
+			counts[p]++
+			if counts[p] > counts[base] {
+				base = p
+			}
+		}
+	}
+	l0 := lightness(base)
+
+	t := color.NRGBAModel.Convert(c).(color.NRGBA)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			p := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			if p.A == 0 {
+				continue
+			}
+			l := lightness(p)
+			shade := func(v uint8) uint8 {
+				if l <= l0 {
+					if l0 == 0 {
+						return v
+					}
+					return uint8(float64(v) * l / l0)
+				}
+				return uint8(float64(v) + float64(0xff-v)*(l-l0)/(1-l0))
+			}
+			dst.Set(x-b.Min.X, y-b.Min.Y, color.NRGBA{shade(t.R), shade(t.G), shade(t.B), p.A})
+		}
+	}
+	return dst
+}
+
+// lightness returns the HSL lightness of the color in range [0, 1].
+func lightness(c color.NRGBA) float64 {
+	max, min := c.R, c.R
+	for _, v := range []uint8{c.G, c.B} {
+		if v > max {
+			max = v
+		}
+		if v < min {
+			min = v
+		}
+	}
+	return (float64(max) + float64(min)) / 2 / 0xff
+}
+
+// fade returns a copy of the image with alpha multiplied by a.
+func fade(src image.Image, a float64) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	for i, v := range dst.Pix {
+		dst.Pix[i] = uint8(float64(v)*a + 0.5)
+	}
+	return dst
+}
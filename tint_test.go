@@ -0,0 +1,49 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestTintPin(t *testing.T) {
+	parts := onmap.DefaultPin()
+	pin := parts[1]
+	green := color.RGBA{0, 0xc0, 0, 0xff}
+	tinted := onmap.TintPin(pin, green)
+
+	b := pin.Bounds()
+	if tinted.Bounds().Size() != b.Size() {
+		t.Fatalf("expected size %v, got %v", b.Size(), tinted.Bounds().Size())
+	}
+	body := color.RGBA{0xff, 0x3b, 0x30, 0xff}
+	found := false
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			src := pin.At(x, y)
+			dst := tinted.At(x-b.Min.X, y-b.Min.Y)
+			_, _, _, sa := src.RGBA()
+			_, _, _, da := dst.RGBA()
+			if sa != da {
+				t.Fatalf("alpha changed at (%d, %d)", x, y)
+			}
+			if sameColor(src, body) {
+				found = true
+				if !sameColor(dst, green) {
+					t.Fatalf("expected %v at (%d, %d), got %v", green, x, y, dst)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Fatal("no pin body color found")
+	}
+
+	// Tinted pin body can be used with the original shadow.
+	m := onmap.Render(solidMap(360, 360, color.White), []image.Image{parts[0], tinted}, []onmap.Coord{{0, 0}}, nil)
+	if countColor(m, green) == 0 {
+		t.Errorf("expected tinted pin on the map")
+	}
+}
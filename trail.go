@@ -0,0 +1,80 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+)
+
+// TrailOption defines options for AnimateTrail.
+type TrailOption struct {
+	// Color is a color of the head of the trail.
+	// The trail fades out towards its tail.
+	Color color.Color
+
+	// Width is a width of the trail in pixels.
+	Width float64
+}
+
+// DefaultTrail is the default trail option.
+var DefaultTrail = &TrailOption{
+	Color: color.RGBA{0xe0, 0x20, 0x20, 0xff},
+	Width: 3,
+}
+
+// AnimateTrail returns the given number of animation frames with the pin
+// moving along the track like a comet: each frame shows the pin at its
+// current position and a fading trail through its positions in up to
+// trailLen previous frames. The crop rectangle is computed once from
+// the whole track, so the framing is stable across frames.
+//
+// If trail is nil, DefaultTrail is used. See Render for
+// the description of pin parts and options.
+func AnimateTrail(worldMap image.Image, pinParts []image.Image, track []Coord, frames, trailLen int, trail *TrailOption, opt *Options) []image.Image {
+	if trail == nil {
+		trail = DefaultTrail
+	}
+	if len(track) == 0 {
+		return nil
+	}
+	// Index of the track point of the pin in each frame.
+	positions := make([]int, frames)
+	for f := range positions {
+		if frames > 1 {
+			positions[f] = int(math.Round(float64(f) * float64(len(track)-1) / float64(frames-1)))
+		}
+	}
+
+	cr, cg, cb, ca := trail.Color.RGBA()
+	out := make([]image.Image, frames)
+	for f := range out {
+		// The pin is followed by the whole track, which defines the crop.
+		m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+			coords: []Coord{track[positions[f]]},
+			mk: func(i int, pt image.Point) marker {
+				return marker{pt: pt, parts: pinParts}
+			},
+			extra: track,
+			onMap: func(m *image.RGBA, cs []image.Point, l layout) {
+				cs = cs[1:]
+				tail := f - trailLen
+				if tail < 0 {
+					tail = 0
+				}
+				for i := tail + 1; i <= f; i++ {
+					// Segments closer to the head are more opaque.
+					a := float64(trailLen-(f-i)) / float64(trailLen)
+					c := color.RGBA64{
+						uint16(float64(cr) * a), uint16(float64(cg) * a),
+						uint16(float64(cb) * a), uint16(float64(ca) * a),
+					}
+					seg := []image.Point{cs[positions[i-1]], cs[positions[i]]}
+					strokePolyline(m, seg, l.px(trail.Width), c)
+				}
+			},
+		})
+		out[f] = finish(m, r, opt)
+	}
+	return out
+}
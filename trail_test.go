@@ -0,0 +1,30 @@
+package onmap_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestAnimateTrail(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	var track []onmap.Coord
+	for long := -60.0; long <= 60; long += 10 {
+		track = append(track, onmap.Coord{0, long})
+	}
+	frames := onmap.AnimateTrail(worldMap, nil, track, len(track), 3, nil, nil)
+	if len(frames) != len(track) {
+		t.Fatalf("expected %d frames, got %d", len(track), len(frames))
+	}
+	var counts []int
+	for _, f := range frames[:6] {
+		counts = append(counts, countNotColor(f, color.White))
+	}
+	if !(counts[0] == 0 && counts[0] < counts[1] && counts[1] < counts[2] && counts[2] < counts[3]) {
+		t.Errorf("expected trail to grow in frames 0-3, got pixel counts %v", counts)
+	}
+	if !(counts[3] == counts[4] && counts[4] == counts[5]) {
+		t.Errorf("expected trail to be capped in frames 3-5, got pixel counts %v", counts)
+	}
+}
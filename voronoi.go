@@ -0,0 +1,69 @@
+package onmap
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// voronoiAlpha is the opacity of Voronoi region tints.
+const voronoiAlpha = 0x60
+
+// defaultVoronoiColors are region colors used if none are given.
+var defaultVoronoiColors = []color.Color{
+	color.RGBA{0xe0, 0x20, 0x20, 0xff},
+	color.RGBA{0x20, 0x60, 0xe0, 0xff},
+	color.RGBA{0x20, 0xa0, 0x40, 0xff},
+	color.RGBA{0xe0, 0xa0, 0x20, 0xff},
+	color.RGBA{0x90, 0x30, 0xc0, 0xff},
+}
+
+// MapVoronoi is like Render, but also tints each pixel of the map
+// (within the crop rectangle) with a translucent color of the pin
+// nearest to it, producing a Voronoi diagram of the projected points.
+// The i-th coordinate gets the color colors[i%len(colors)]. If colors
+// is empty, a default palette is used. Pins are drawn on top.
+func MapVoronoi(worldMap image.Image, pinParts []image.Image, coords []Coord, colors []color.Color, opt *Options) image.Image {
+	if len(colors) == 0 {
+		colors = defaultVoronoiColors
+	}
+	m, r, _ := renderScene(context.Background(), worldMap, opt, &scene{
+		coords: coords,
+		mk: func(i int, pt image.Point) marker {
+			return marker{pt: pt, parts: pinParts}
+		},
+		beneath: func(m *image.RGBA, cs []image.Point, l layout) {
+			if len(cs) > 0 {
+				drawVoronoi(m, cs, colors)
+			}
+		},
+	})
+	return finish(m, r, opt)
+}
+
+// drawVoronoi tints each pixel of the image with a translucent color
+// of the point nearest to it.
+func drawVoronoi(m *image.RGBA, cs []image.Point, colors []color.Color) {
+	r := m.Rect
+	tints := make([]color.NRGBA, len(cs))
+	for i := range cs {
+		c := color.NRGBAModel.Convert(colors[i%len(colors)]).(color.NRGBA)
+		c.A = voronoiAlpha
+		tints[i] = c
+	}
+	overlay := image.NewNRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			nearest, best := 0, -1
+			for i, c := range cs {
+				dx, dy := x-c.X, y-c.Y
+				if d := dx*dx + dy*dy; best < 0 || d < best {
+					nearest, best = i, d
+				}
+			}
+			overlay.SetNRGBA(x, y, tints[nearest])
+		}
+	}
+	draw.Draw(m, r, overlay, r.Min, draw.Over)
+}
@@ -0,0 +1,30 @@
+package onmap_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestMapVoronoi(t *testing.T) {
+	worldMap := solidMap(360, 360, color.White)
+	coords := []onmap.Coord{{0, -20}, {0, 20}}
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	m := onmap.MapVoronoi(worldMap, nil, coords, []color.Color{red, blue}, nil)
+
+	// Points are at x=160 and x=200, so the boundary is at x=180.
+	isReddish := func(c color.Color) bool {
+		r, _, b, _ := c.RGBA()
+		return r > b
+	}
+	for _, y := range []int{20, 180, 340} {
+		if c := m.At(177, y); !isReddish(c) {
+			t.Errorf("expected red region at (177, %d), got %v", y, c)
+		}
+		if c := m.At(183, y); isReddish(c) {
+			t.Errorf("expected blue region at (183, %d), got %v", y, c)
+		}
+	}
+}
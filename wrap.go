@@ -0,0 +1,53 @@
+package onmap
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// wrapShift returns the number of pixels to roll the map rectangle
+// horizontally by to move its left and right edges into the widest gap
+// between the points, or zero if the widest gap is already at the edges.
+func wrapShift(cs []image.Point, r image.Rectangle) int {
+	if len(cs) < 2 {
+		return 0
+	}
+	w := r.Dx()
+	xs := make([]int, len(cs))
+	for i, c := range cs {
+		xs[i] = mod(c.X-r.Min.X, w)
+	}
+	sort.Ints(xs)
+	// Gap across the edges.
+	widest := xs[0] + w - xs[len(xs)-1]
+	shift := 0
+	for i := 1; i < len(xs); i++ {
+		if gap := xs[i] - xs[i-1]; gap > widest {
+			widest = gap
+			shift = w - (xs[i-1]+xs[i])/2
+		}
+	}
+	return shift
+}
+
+// drawRolled draws the source image starting at sp into the rectangle r
+// of dst, rolled horizontally by the given number of pixels, so that
+// pixels moved past the right edge of r wrap around to its left edge.
+func drawRolled(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point, shift int, op draw.Op) {
+	shift = mod(shift, r.Dx())
+	split := r.Max.X - shift
+	draw.Draw(dst, image.Rect(r.Min.X+shift, r.Min.Y, r.Max.X, r.Max.Y), src, sp, op)
+	if shift != 0 {
+		draw.Draw(dst, image.Rect(r.Min.X, r.Min.Y, r.Min.X+shift, r.Max.Y), src, sp.Add(image.Point{split - r.Min.X, 0}), op)
+	}
+}
+
+// mod returns x modulo n in range [0, n).
+func mod(x, n int) int {
+	x %= n
+	if x < 0 {
+		x += n
+	}
+	return x
+}
@@ -0,0 +1,96 @@
+package onmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dchest/onmap"
+)
+
+func TestWrapAntimeridian(t *testing.T) {
+	coords := []onmap.Coord{
+		{-17, 178},  // Fiji
+		{-13, -172}, // Samoa
+	}
+	mapWidth := onmap.DefaultMap().Bounds().Dx()
+
+	// Without wrapping, the crop spans nearly the whole map.
+	m := onmap.Pins(coords, &onmap.CropOption{Bound: 50})
+	if w := m.Bounds().Dx(); w < mapWidth*9/10 {
+		t.Errorf("expected crop spanning the map, got width %d", w)
+	}
+
+	m = onmap.Pins(coords, &onmap.CropOption{Bound: 50, WrapAntimeridian: true})
+	// 10° of longitude plus bounds.
+	if w, max := m.Bounds().Dx(), mapWidth*10/360+2*50+2; w > max {
+		t.Errorf("expected tight crop of width at most %d, got %d", max, w)
+	}
+	if n := countColor(m, color.RGBA{0xff, 0x3b, 0x30, 0xff}); n == 0 {
+		t.Errorf("expected pins in the wrapped crop")
+	}
+
+	// Points not near the antimeridian are not wrapped.
+	europe := []onmap.Coord{{42.1, 19.1}, {41.9097306, 12.2558141}}
+	crop := &onmap.CropOption{Bound: 50}
+	a := onmap.Pins(europe, crop)
+	crop.WrapAntimeridian = true
+	b := onmap.Pins(europe, crop)
+	if a.Bounds() != b.Bounds() {
+		t.Errorf("expected same crop %v, got %v", a.Bounds(), b.Bounds())
+	}
+}
+
+func TestWrapAntimeridianPositions(t *testing.T) {
+	coords := []onmap.Coord{{-17, 178}, {-14, -171}}
+	worldMap := onmap.DefaultMap()
+	w, h := worldMap.Bounds().Dx(), worldMap.Bounds().Dy()
+	crop := &onmap.CropOption{Bound: 20, WrapAntimeridian: true}
+
+	m, pts := onmap.MapPinsPositions(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, crop)
+	size := image.Rectangle{Max: m.Bounds().Size()}
+	for i, p := range pts {
+		if !p.In(size) {
+			t.Errorf("%v: position %v is outside of the image %v", coords[i], p, size)
+		}
+	}
+
+	r := onmap.CropRect(onmap.Mercator, w, h, coords, crop)
+	if r != m.Bounds() {
+		t.Errorf("expected crop rectangle %v, got %v", m.Bounds(), r)
+	}
+	res := onmap.MapPinsResult(onmap.Mercator, worldMap, onmap.DefaultPin(), coords, crop)
+	if res.Shift == 0 {
+		t.Errorf("expected rolled map")
+	}
+	l := onmap.NewCropLocator(onmap.Mercator, w, h, coords, crop)
+	for i, c := range coords {
+		p, ok := l.Pixel(c)
+		if !ok {
+			t.Errorf("%v: expected pixel %v inside the crop", c, p)
+		}
+		if p != pts[i] {
+			t.Errorf("%v: expected pixel %v, got %v", c, pts[i], p)
+		}
+	}
+}
+
+func TestWrapAntimeridianResult(t *testing.T) {
+	coords := []onmap.Coord{{-17, 178}, {-14, -171}}
+	crop := &onmap.CropOption{Bound: 20, WrapAntimeridian: true}
+	res := onmap.MapPinsResult(onmap.Mercator, onmap.DefaultMap(), onmap.DefaultPin(), coords, crop)
+	if !res.HasCorners {
+		t.Fatalf("expected corners for Mercator")
+	}
+	// The crop crosses the date line, so the west corner is in
+	// the eastern hemisphere and the east corner in the western one.
+	nw, se := res.NorthWest, res.SouthEast
+	if nw.Long < 170 || nw.Long > 178 || se.Long < -171 || se.Long > -160 {
+		t.Errorf("expected corners across the date line, got %v, %v", nw, se)
+	}
+	for _, c := range coords {
+		if c.Lat > nw.Lat || c.Lat < se.Lat {
+			t.Errorf("%v is outside of corners %v, %v", c, nw, se)
+		}
+	}
+}